@@ -0,0 +1,107 @@
+package database
+
+import (
+	"social-network/structs"
+	"testing"
+)
+
+// expectBookmarkCount checks the size GetBookmarkCollections reports for the user's only collection
+func expectBookmarkCount(t *testing.T, userId, want int) {
+	t.Helper()
+	collections, err := GetBookmarkCollections(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 1 || collections[0].Bookmarks != want {
+		t.Fatalf("collections = %+v, want one with %d bookmarks", collections, want)
+	}
+}
+
+func TestDeletePostRemovesItsBookmarks(t *testing.T) {
+	openTestDB(t)
+	userId := createTestUser(t, "reader@test.com")
+	collectionId, err := GetOrCreateDefaultCollection(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := AddPost(structs.Post{UserId: userId, Title: "Post", Content: "content", Privacy: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InsertBookmark(structs.Bookmark{UserId: userId, CollectionId: collectionId, PostId: post.Id}); err != nil {
+		t.Fatal(err)
+	}
+	expectBookmarkCount(t, userId, 1)
+
+	if err := DeletePost(post.Id); err != nil {
+		t.Fatal(err)
+	}
+	expectBookmarkCount(t, userId, 0)
+}
+
+func TestDeleteGroupPostRemovesItsBookmarks(t *testing.T) {
+	openTestDB(t)
+	collectionId, err := GetOrCreateDefaultCollection(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := AddGroupPost(structs.GroupPost{GroupId: 1, UserId: 2, Title: "Post", Content: "content", Status: "published"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InsertBookmark(structs.Bookmark{UserId: 2, CollectionId: collectionId, GroupPostId: post.Id}); err != nil {
+		t.Fatal(err)
+	}
+	expectBookmarkCount(t, 2, 1)
+
+	if err := DeleteGroupPost(post.Id); err != nil {
+		t.Fatal(err)
+	}
+	expectBookmarkCount(t, 2, 0)
+}
+
+func TestReorderBookmarksNeedsTheWholeCollection(t *testing.T) {
+	openTestDB(t)
+	userId := createTestUser(t, "reader@test.com")
+	collectionId, err := GetOrCreateDefaultCollection(userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for i := 0; i < 3; i++ {
+		post, err := AddPost(structs.Post{UserId: userId, Title: "Post", Content: "content", Privacy: "public"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		bookmark, err := InsertBookmark(structs.Bookmark{UserId: userId, CollectionId: collectionId, PostId: post.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, bookmark.Id)
+	}
+
+	for _, order := range [][]int{
+		{ids[2], ids[0]},
+		{ids[2], ids[2], ids[0]},
+		{ids[2], ids[1], ids[0], ids[0]},
+	} {
+		if err := ReorderBookmarks(collectionId, order); err == nil {
+			t.Errorf("order %v was accepted", order)
+		}
+	}
+
+	if err := ReorderBookmarks(collectionId, []int{ids[2], ids[0], ids[1]}); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err := ReadBookmarks(collectionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int{ids[2], ids[0], ids[1]} {
+		if bookmarks[i].Id != want || bookmarks[i].Position != i+1 {
+			t.Fatalf("bookmarks = %+v, want ids %d, %d, %d", bookmarks, ids[2], ids[0], ids[1])
+		}
+	}
+}
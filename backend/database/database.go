@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -20,25 +21,32 @@ import (
 var DB *sql.DB
 
 func InitDB() {
-	var err error
-	absDBPath := "database/data.db"
-	DB, err = sql.Open("sqlite3", absDBPath)
-	if err != nil {
+	if err := OpenDB("database/data.db", "file://database/migrations"); err != nil {
 		log.Fatal(err)
 	}
+}
 
-	migrationsDir := "file://database/migrations"
+// OpenDB opens the database at dbPath and applies the migrations found in migrationsDir,
+// tests use it to run against a fresh database file
+func OpenDB(dbPath, migrationsDir string) error {
+	var err error
+	DB, err = sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
 
-	m, err := migrate.New(migrationsDir, "sqlite3://"+absDBPath)
+	m, err := migrate.New(migrationsDir, "sqlite3://"+dbPath)
 	if err != nil {
-		log.Fatalf("migration initialization failed: %v", err)
+		return fmt.Errorf("migration initialization failed: %v", err)
 	}
+	defer m.Close()
 
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatalf("migration failed: %v", err)
+		return fmt.Errorf("migration failed: %v", err)
 	} else if err == migrate.ErrNoChange {
 		log.Println("No migrations to apply.")
 	}
+	return nil
 }
 
 func InsertUser(firstName, lastName, email string, dateOfBirth string, nickname, avatar, aboutMe *string, isPrivate bool, hashedPassword []byte) error {
@@ -89,65 +97,104 @@ func InsertSessionToken(session structs.Session) error {
 	return nil
 }
 
-func InsertComment(comment structs.Comment) (structs.Post, error) {
+// authorNameColumn is the display name of the user joined as "u": the nickname, or the full name if there is none.
+// Author data is always read from users, so profile changes show up on old posts and comments too.
+const authorNameColumn = `CASE WHEN COALESCE(u.nickname, '') = '' THEN u.first_name || ' ' || u.last_name ELSE u.nickname END`
+
+// InsertComment stores the comment and returns the updated post together with the new comment id
+func InsertComment(comment structs.Comment) (structs.Post, int, error) {
 	stmt, err := DB.Prepare(`
-		INSERT INTO comments (post_id, user_id, user_avatar, creator_name, content, photo)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO comments (post_id, user_id, content, photo, parent_id, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 	`)
 	if err != nil {
-		return structs.Post{}, err
+		return structs.Post{}, 0, err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(comment.PostId, comment.UserId, comment.ProfilePicture, comment.CreatorName, comment.Content, comment.Photo)
+	respFromDb, err := stmt.Exec(comment.PostId, comment.UserId, comment.Content, comment.Photo, comment.ParentId)
 	if err != nil {
-		return structs.Post{}, err
+		return structs.Post{}, 0, err
 	}
 
-	var updatedPost structs.Post
-	err = DB.QueryRow(`
-		SELECT * FROM posts
-		WHERE id = ?
-	`, comment.PostId).Scan(&updatedPost.Id, &updatedPost.UserId, &updatedPost.ProfilePicture, &updatedPost.Title, &updatedPost.Content, &updatedPost.Photo, &updatedPost.Privacy)
+	commentId, err := respFromDb.LastInsertId()
 	if err != nil {
-		return structs.Post{}, err
+		return structs.Post{}, 0, err
+	}
+
+	updatedPost, err := GetPostById(comment.PostId)
+	if err != nil {
+		return structs.Post{}, 0, err
+	}
+	if updatedPost == nil {
+		return structs.Post{}, 0, sql.ErrNoRows
 	}
 	updatedPost.Comments, err = ReadAllComments(comment.PostId)
 	if err != nil {
-		return structs.Post{}, err
+		return structs.Post{}, 0, err
 	}
 
-	return updatedPost, nil
+	return *updatedPost, int(commentId), nil
 }
 
+// ReadAllComments returns the comments of a post as a tree, newest top level comments first and replies in the order they were written
 func ReadAllComments(postId int) ([]structs.Comment, error) {
-	comments := make([]structs.Comment, 0)
 	rows, err := DB.Query(`
-		SELECT *
-		FROM comments
-		WHERE post_id = ?
-		ORDER BY id DESC
+		SELECT c.id, c.post_id, c.user_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM comment_reactions cr WHERE cr.comment_id = c.id), c.hidden
+		FROM comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.post_id = ?
+		ORDER BY c.id
 	`, postId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	ids := make(map[int]bool)
+	children := make(map[int][]structs.Comment)
 	for rows.Next() {
 		var comment structs.Comment
-		err := rows.Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo)
+		var hidden bool
+		err := rows.Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.Reactions, &hidden)
 		if err != nil {
 			return nil, err
 		}
-		comments = append(comments, comment)
+		ids[comment.Id] = true
+		// a hidden comment is left out of the tree and takes its replies with it
+		if hidden {
+			continue
+		}
+		parentId := comment.ParentId
+		if !ids[parentId] {
+			parentId = 0
+		}
+		children[parentId] = append(children[parentId], comment)
 	}
-	if len(comments) == 0 {
-		return comments, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	comments := nestComments(0, children)
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	if comments == nil {
+		return make([]structs.Comment, 0), nil
+	}
 	return comments, nil
 }
 
+func nestComments(parentId int, children map[int][]structs.Comment) []structs.Comment {
+	comments := children[parentId]
+	for i := range comments {
+		comments[i].Replies = nestComments(comments[i].Id, children)
+		comments[i].ReplyCount = len(comments[i].Replies)
+	}
+	return comments
+}
+
 func InsertChatMessage(chatMessage structs.ChatMessage) (structs.ChatMessage, error) {
 	stmt, err := DB.Prepare(`
 		INSERT INTO chat_messages (sender_id, content, private_chat_id, group_chat_id)
@@ -195,13 +242,17 @@ func UpdateAvatar(userId int, avatarURL string) error {
 
 func GetUserIdAndAuthStatus(sessionToken string) (int, bool) {
 	var (
-		userId     int
-		expiration time.Time
+		userId         int
+		expiration     time.Time
+		suspendedUntil *time.Time
 	)
 
 	err := DB.QueryRow(`
-		SELECT user_id, expiration FROM sessions WHERE session_token = ?
-	`, sessionToken).Scan(&userId, &expiration)
+		SELECT s.user_id, s.expiration, u.suspended_until
+		FROM sessions s
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE s.session_token = ?
+	`, sessionToken).Scan(&userId, &expiration, &suspendedUntil)
 
 	if err == sql.ErrNoRows || err != nil {
 		return 0, false
@@ -211,14 +262,18 @@ func GetUserIdAndAuthStatus(sessionToken string) (int, bool) {
 		return 0, false
 	}
 
+	if suspendedUntil != nil && suspendedUntil.After(time.Now()) {
+		return 0, false
+	}
+
 	return userId, true
 }
 
 func GetUserByEmail(email string) (*structs.User, error) {
 	var user structs.User
 	err := DB.QueryRow(`
-		SELECT * FROM users WHERE email = ?
-	`, email).Scan(&user.Id, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.DateOfBirth, &user.Nickname, &user.Avatar, &user.AboutMe, &user.IsPrivate)
+		SELECT id, first_name, last_name, email, password, date_of_birth, nickname, avatar, about_me, is_private, role FROM users WHERE email = ?
+	`, email).Scan(&user.Id, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.DateOfBirth, &user.Nickname, &user.Avatar, &user.AboutMe, &user.IsPrivate, &user.Role)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -244,8 +299,8 @@ func GetUserNameById(userId int) (string, error) {
 func GetUserById(userId int) (*structs.User, error) {
 	var user structs.User
 	err := DB.QueryRow(`
-		SELECT * FROM users WHERE ID = ?
-	`, userId).Scan(&user.Id, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.DateOfBirth, &user.Nickname, &user.Avatar, &user.AboutMe, &user.IsPrivate)
+		SELECT id, first_name, last_name, email, password, date_of_birth, nickname, avatar, about_me, is_private, role FROM users WHERE id = ?
+	`, userId).Scan(&user.Id, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.DateOfBirth, &user.Nickname, &user.Avatar, &user.AboutMe, &user.IsPrivate, &user.Role)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -309,20 +364,27 @@ func DeleteUserFromSessions(userId int) error {
 	return nil
 }
 
+// ReadAllPosts builds the home feed: public posts, the viewer's own posts, the posts of the people they follow that
+// they are allowed to see, and public posts tagged with a tag the viewer follows
 func ReadAllPosts(userID int) ([]structs.Post, error) {
 	posts := make([]structs.Post, 0)
 	postedIDs := make(map[int]bool)
 	rows, err := DB.Query(`
-		SELECT p.id, p.user_id, p.user_avatar, p.title, p.content, p.photo, p.privacy
+		SELECT p.id, p.user_id, COALESCE(u.avatar, ''), p.title, p.content, p.photo, p.privacy, p.repost_of
 		FROM posts p
-		LEFT JOIN user_following uf ON (p.user_id = uf.following_id OR p.user_id = uf.follower_id) AND uf.follower_id = ?
-		WHERE (
-			p.privacy = 'public' OR 
-			(p.privacy = 'private' AND (uf.follower_id = ? OR p.user_id = ?)) OR 
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.status = 'published' AND (
+			p.privacy = 'public' OR
 			p.user_id = ? OR
-			(p.privacy NOT LIKE 'private' AND p.privacy NOT LIKE 'public'))
-		ORDER BY id DESC
-	`, userID, userID, userID, "%"+strconv.Itoa(userID)+"%")
+			(p.privacy IN ('public', 'private') AND EXISTS(
+				SELECT 1 FROM user_following WHERE follower_id = ? AND following_id = p.user_id)) OR
+			(p.privacy = 'public' AND EXISTS(
+				SELECT 1 FROM post_tags pt
+				JOIN tag_followers tf ON tf.tag = pt.tag
+				WHERE pt.post_id = p.id AND tf.user_id = ?)) OR
+			(',' || p.privacy || ',') LIKE '%,' || ? || ',%')
+		ORDER BY p.id DESC
+	`, userID, userID, userID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -330,7 +392,7 @@ func ReadAllPosts(userID int) ([]structs.Post, error) {
 
 	for rows.Next() {
 		var post structs.Post
-		err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy)
+		err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy, &post.RepostOf)
 		if err != nil {
 			return nil, err
 		}
@@ -357,6 +419,9 @@ func ReadAllPosts(userID int) ([]structs.Post, error) {
 			if err != nil {
 				return nil, err
 			}
+			if err := attachRepostInfo(&post); err != nil {
+				return nil, err
+			}
 			posts = append(posts, post)
 			postedIDs[post.Id] = true
 		}
@@ -370,10 +435,11 @@ func ReadAllPosts(userID int) ([]structs.Post, error) {
 func GetPostsByUserId(userId int) ([]structs.Post, error) {
 	posts := make([]structs.Post, 0)
 	rows, err := DB.Query(`
-		SELECT id, user_id, user_avatar, title, content, photo, privacy 
-		FROM posts
-		WHERE user_id = ?
-		ORDER BY id DESC
+		SELECT p.id, p.user_id, COALESCE(u.avatar, ''), p.title, p.content, p.photo, p.privacy, p.repost_of, p.pinned_at IS NOT NULL
+		FROM posts p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.user_id = ? AND p.status = 'published'
+		ORDER BY p.pinned_at IS NULL, p.pinned_at DESC, p.id DESC
 	`, userId)
 	if err != nil {
 		return nil, err
@@ -382,7 +448,7 @@ func GetPostsByUserId(userId int) ([]structs.Post, error) {
 
 	for rows.Next() {
 		var post structs.Post
-		err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy)
+		err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy, &post.RepostOf, &post.Pinned)
 		if err == sql.ErrNoRows {
 			return []structs.Post{}, nil
 		} else if err != nil {
@@ -395,19 +461,23 @@ func GetPostsByUserId(userId int) ([]structs.Post, error) {
 		if post.Privacy != "public" && post.Privacy != "private" {
 			post.Privacy = "private"
 		}
+		if err := attachRepostInfo(&post); err != nil {
+			return nil, err
+		}
 		posts = append(posts, post)
 	}
 	return posts, nil
 }
 
-func GetGroupByUserId(userId int) ([]structs.Group, error) {
+// GetGroupByUserId lists the groups the user is a member of, leaving out the secret ones the viewer isn't in
+func GetGroupByUserId(userId, viewerId int) ([]structs.Group, error) {
 	groups := make([]structs.Group, 0)
 	rows, err := DB.Query(`
 		SELECT g.id, g.name, g.description, g.creator_id
 		FROM groups g
 		JOIN group_members gm ON g.id = gm.group_id
-		WHERE gm.requester_id = ?
-	`, userId)
+		WHERE gm.requester_id = ? AND `+groupListedCondition+`
+	`, userId, viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -425,15 +495,19 @@ func GetGroupByUserId(userId int) ([]structs.Group, error) {
 
 func AddPost(post structs.Post) (structs.Post, error) {
 	stmt, err := DB.Prepare(`
-		INSERT INTO posts (user_id, user_avatar, title, content, photo, privacy) 
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO posts (user_id, title, content, photo, privacy, repost_of, status, publish_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return structs.Post{}, err
 	}
 	defer stmt.Close()
 
-	respFromDb, err := stmt.Exec(post.UserId, post.ProfilePicture, post.Title, post.Content, post.Photo, post.Privacy)
+	if post.Status == "" {
+		post.Status = "published"
+	}
+
+	respFromDb, err := stmt.Exec(post.UserId, post.Title, post.Content, post.Photo, post.Privacy, post.RepostOf, post.Status, post.PublishAt)
 	if err != nil {
 		return structs.Post{}, err
 	}
@@ -443,32 +517,36 @@ func AddPost(post structs.Post) (structs.Post, error) {
 		return structs.Post{}, err
 	}
 
-	var retrievedPost structs.Post
-	err = DB.QueryRow(`
-		SELECT * FROM posts
-		WHERE id = ?
-	`, id).Scan(&retrievedPost.Id, &retrievedPost.UserId, &retrievedPost.ProfilePicture, &retrievedPost.Title, &retrievedPost.Content, &retrievedPost.Photo, &retrievedPost.Privacy)
+	retrievedPost, err := GetPostById(int(id))
 	if err != nil {
 		return structs.Post{}, err
 	}
-	retrievedPost.Comments, err = ReadAllComments(post.Id)
+	if retrievedPost == nil {
+		return structs.Post{}, sql.ErrNoRows
+	}
+	retrievedPost.Comments, err = ReadAllComments(retrievedPost.Id)
 	if err != nil {
 		return structs.Post{}, err
 	}
+	if err := attachRepostInfo(retrievedPost); err != nil {
+		return structs.Post{}, err
+	}
 	if !(retrievedPost.Privacy == "public" || retrievedPost.Privacy == "private") {
 		retrievedPost.Privacy = "private"
 	}
 
-	return retrievedPost, nil
+	return *retrievedPost, nil
 }
 
-func ReadAllGroups() ([]structs.Group, error) {
+// ReadAllGroups lists every group the viewer is allowed to find, secret groups only show up for their members
+func ReadAllGroups(viewerId int) ([]structs.Group, error) {
 	groups := make([]structs.Group, 0)
 	rows, err := DB.Query(`
-		SELECT id, name, description, creator_id
-		FROM groups
-		ORDER BY id DESC
-	`)
+		SELECT g.id, g.name, g.description, g.cover, g.creator_id, g.archived_at IS NOT NULL, g.visibility
+		FROM groups g
+		WHERE `+groupListedCondition+`
+		ORDER BY g.id DESC
+	`, viewerId)
 	if err != nil {
 		return nil, fmt.Errorf("error querying the database: %v", err)
 	}
@@ -476,7 +554,7 @@ func ReadAllGroups() ([]structs.Group, error) {
 
 	for rows.Next() {
 		var group structs.Group
-		if err := rows.Scan(&group.Id, &group.Name, &group.Description, &group.CreatorId); err != nil {
+		if err := rows.Scan(&group.Id, &group.Name, &group.Description, &group.Cover, &group.CreatorId, &group.Archived, &group.Visibility); err != nil {
 			return nil, fmt.Errorf("error scanning row: %v", err)
 		}
 		membRows, err := DB.Query(`
@@ -510,10 +588,10 @@ func ReadAllGroups() ([]structs.Group, error) {
 func ReadGroup(groupId int) (*structs.Group, error) {
 	var group structs.Group
 	err := DB.QueryRow(`
-		SELECT id, name, description, creator_id
+		SELECT id, name, description, cover, rules, creator_id, archived_at IS NOT NULL, visibility, pending_owner_id, post_approval
 		FROM groups
 		WHERE id = ?
-	`, groupId).Scan(&group.Id, &group.Name, &group.Description, &group.CreatorId)
+	`, groupId).Scan(&group.Id, &group.Name, &group.Description, &group.Cover, &group.Rules, &group.CreatorId, &group.Archived, &group.Visibility, &group.PendingOwnerId, &group.PostApproval)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("no group found for ID: %d", groupId)
 	} else if err != nil {
@@ -547,15 +625,15 @@ func ReadGroup(groupId int) (*structs.Group, error) {
 
 func AddGroup(group structs.Group) (structs.Group, error) {
 	stmt, err := DB.Prepare(`
-		INSERT INTO groups (name, description, creator_id) 
-		VALUES (?, ?, ?)
+		INSERT INTO groups (name, description, creator_id, visibility)
+		VALUES (?, ?, ?, ?)
 	`)
 	if err != nil {
 		return structs.Group{}, fmt.Errorf("error preparing SQL statement: %v", err)
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(group.Name, group.Description, group.CreatorId)
+	result, err := stmt.Exec(group.Name, group.Description, group.CreatorId, group.Visibility)
 	if err != nil {
 		return structs.Group{}, fmt.Errorf("error executing SQL statement: %v", err)
 	}
@@ -569,12 +647,15 @@ func AddGroup(group structs.Group) (structs.Group, error) {
 	if err := AddUserToGroup(group.CreatorId, int(lastInsertID)); err != nil {
 		return structs.Group{}, fmt.Errorf("error adding user to group: %v", err)
 	}
+	if err := SetGroupMemberRole(group.CreatorId, int(lastInsertID), "owner"); err != nil {
+		return structs.Group{}, err
+	}
 
 	var retrievedGroup structs.Group
 	err = DB.QueryRow(`
-		SELECT * FROM groups
+		SELECT id, name, description, creator_id, visibility FROM groups
 		WHERE id = ?
-	`, lastInsertID).Scan(&retrievedGroup.Id, &retrievedGroup.Name, &retrievedGroup.Description, &retrievedGroup.CreatorId)
+	`, lastInsertID).Scan(&retrievedGroup.Id, &retrievedGroup.Name, &retrievedGroup.Description, &retrievedGroup.CreatorId, &retrievedGroup.Visibility)
 	if err != nil {
 		return structs.Group{}, err
 	}
@@ -597,6 +678,7 @@ func GetJoinRequestStatus(requesterID, groupId int) (string, error) {
 	err := DB.QueryRow(`
 		SELECT status FROM join_requests
 		WHERE requester_id = ? AND group_id = ?
+		ORDER BY id DESC LIMIT 1
 	`, requesterID, groupId).Scan(&status)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -607,46 +689,85 @@ func GetJoinRequestStatus(requesterID, groupId int) (string, error) {
 	return status, nil
 }
 
-// InsertJoinRequest inserts a new join request
-func InsertJoinRequest(requesterID, groupID int) error {
-	stmt, err := DB.Prepare(`
-        INSERT INTO join_requests (requester_id, group_id, status) 
-        VALUES (?, ?, ?)
-    `)
+// InsertJoinRequest inserts a new join request, kind is "request" or "invitation" and answers are the applicant's
+// answers to the membership questions. It replaces the user's previous request to the group, which has been answered by then.
+func InsertJoinRequest(requesterID, groupID int, kind string, answers []structs.JoinRequestAnswer) error {
+	tx, err := DB.Begin()
 	if err != nil {
-		return fmt.Errorf("error preparing SQL statement: %v", err)
+		return err
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(requesterID, groupID, "pending")
+	if err := insertJoinRequest(tx, requesterID, groupID, kind, answers); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertJoinRequest(tx *sql.Tx, requesterID, groupID int, kind string, answers []structs.JoinRequestAnswer) error {
+	if answers == nil {
+		answers = []structs.JoinRequestAnswer{}
+	}
+	answersJSON, err := json.Marshal(answers)
 	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM join_requests WHERE requester_id = ? AND group_id = ?
+	`, requesterID, groupID); err != nil {
+		return fmt.Errorf("error replacing join request: %v", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO join_requests (requester_id, group_id, status, kind, answers, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, requesterID, groupID, "pending", kind, string(answersJSON), time.Now().UTC()); err != nil {
 		return fmt.Errorf("error executing SQL statement: %v", err)
 	}
 	return nil
 }
 
+// GetJoinRequest returns the user's latest request or invitation to the group, nil if there is none
+func GetJoinRequest(requesterID, groupID int) (*structs.GroupJoinRequest, error) {
+	var request structs.GroupJoinRequest
+	err := DB.QueryRow(`
+		SELECT requester_id, group_id, kind, status, created_at, responded_at
+		FROM join_requests
+		WHERE requester_id = ? AND group_id = ?
+		ORDER BY id DESC LIMIT 1
+	`, requesterID, groupID).Scan(&request.UserId, &request.GroupId, &request.Kind, &request.Status, &request.CreatedAt, &request.RespondedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting join request: %v", err)
+	}
+	return &request, nil
+}
+
 // RespondToJoinRequest updates the status of a join request
 func RespondToJoinRequest(requesterID, groupID int, status, reqType string) error {
 	_, err := DB.Exec(`
 		UPDATE join_requests
-		SET status = ?
-		WHERE requester_id = ? AND group_id = ?
-	`, status, requesterID, groupID)
+		SET status = ?, responded_at = ?
+		WHERE requester_id = ? AND group_id = ? AND status = 'pending'
+	`, status, time.Now().UTC(), requesterID, groupID)
 	if err != nil {
 		return fmt.Errorf("error updating join request status: %v", err)
 	}
+	// only the pending notification is answered, the ones of earlier withdrawn or expired requests keep their status
 	if reqType == "join_request" {
 		_, err = DB.Exec(`
 		UPDATE group_notifications
 		SET status = ?
-		WHERE sender_id = ? AND group_id = ?
+		WHERE sender_id = ? AND group_id = ? AND type = 'join_request' AND status = 'pending'
 	`, status, requesterID, groupID)
 	}
 	if reqType == "invite_group_request" {
 		_, err = DB.Exec(`
 		UPDATE group_notifications
 		SET status = ?
-		WHERE receiver_id = ? AND group_id = ?
+		WHERE receiver_id = ? AND group_id = ? AND type = 'invite_group_request' AND status = 'pending'
 	`, status, requesterID, groupID)
 	}
 	if err != nil {
@@ -708,14 +829,16 @@ func CheckUserIfMemberOfGroup(requesterID int, groupId int) (bool, error) {
 	return count > 0, nil
 }
 
-func ReadAllGroupPosts(groupId int) ([]structs.GroupPost, error) {
+// ReadAllGroupPosts returns the published posts of the group, and the viewer's own posts still waiting for approval
+func ReadAllGroupPosts(groupId, viewerId int) ([]structs.GroupPost, error) {
 	posts := make([]structs.GroupPost, 0)
 	rows, err := DB.Query(`
-		SELECT id, group_id, user_id, user_avatar, title, content, photo
-		FROM group_posts
-		WHERE group_id = ?
-		ORDER BY id DESC
-	`, groupId)
+		SELECT gp.id, gp.group_id, gp.user_id, COALESCE(u.avatar, ''), gp.title, gp.content, gp.photo, gp.status, gp.pinned_at IS NOT NULL
+		FROM group_posts gp
+		LEFT JOIN users u ON u.id = gp.user_id
+		WHERE gp.group_id = ? AND (gp.status = 'published' OR (gp.status = 'pending' AND gp.user_id = ?))
+		ORDER BY gp.pinned_at IS NULL, gp.pinned_at DESC, gp.id DESC
+	`, groupId, viewerId)
 	if err != nil {
 		return nil, fmt.Errorf("error querying group posts: %v", err)
 	}
@@ -723,7 +846,7 @@ func ReadAllGroupPosts(groupId int) ([]structs.GroupPost, error) {
 
 	for rows.Next() {
 		var post structs.GroupPost
-		err := rows.Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo)
+		err := rows.Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Status, &post.Pinned)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning group post rows: %v", err)
 		}
@@ -742,15 +865,19 @@ func ReadAllGroupPosts(groupId int) ([]structs.GroupPost, error) {
 
 func AddGroupPost(post structs.GroupPost) (structs.GroupPost, error) {
 	stmt, err := DB.Prepare(`
-		INSERT INTO group_posts (group_id, user_id, user_avatar, title, content, photo) 
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO group_posts (group_id, user_id, title, content, photo, status, publish_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return structs.GroupPost{}, err
 	}
 	defer stmt.Close()
 
-	respFromDb, err := stmt.Exec(post.GroupId, post.UserId, post.ProfilePicture, post.Title, post.Content, post.Photo)
+	if post.Status == "" {
+		post.Status = "published"
+	}
+
+	respFromDb, err := stmt.Exec(post.GroupId, post.UserId, post.Title, post.Content, post.Photo, post.Status, post.PublishAt)
 	if err != nil {
 		return structs.GroupPost{}, err
 	}
@@ -760,81 +887,115 @@ func AddGroupPost(post structs.GroupPost) (structs.GroupPost, error) {
 		return structs.GroupPost{}, err
 	}
 
-	var retrievedPost structs.GroupPost
-	err = DB.QueryRow(`
-		SELECT * FROM group_posts
-		WHERE id = ?
-	`, id).Scan(&retrievedPost.Id, &retrievedPost.GroupId, &retrievedPost.UserId, &retrievedPost.ProfilePicture, &retrievedPost.Title, &retrievedPost.Content, &retrievedPost.Photo)
+	retrievedPost, err := GetGroupPostById(int(id))
 	if err != nil {
 		return structs.GroupPost{}, err
 	}
+	if retrievedPost == nil {
+		return structs.GroupPost{}, sql.ErrNoRows
+	}
 	retrievedPost.Comments, err = ReadAllGroupComments(retrievedPost.Id, retrievedPost.GroupId)
 	if err != nil {
 		return structs.GroupPost{}, err
 	}
 
-	return retrievedPost, nil
+	return *retrievedPost, nil
 }
 
-func InsertGroupComment(comment structs.GroupComment) (structs.GroupPost, error) {
+// InsertGroupComment stores the comment and returns the updated group post together with the new comment id
+func InsertGroupComment(comment structs.GroupComment) (structs.GroupPost, int, error) {
 	stmt, err := DB.Prepare(`
-		INSERT INTO group_comments (post_id, user_id, user_avatar, group_id, creator_name, content, photo)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO group_comments (post_id, user_id, group_id, content, photo, parent_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 	`)
 	if err != nil {
-		return structs.GroupPost{}, err
+		return structs.GroupPost{}, 0, err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(comment.PostId, comment.UserId, comment.ProfilePicture, comment.GroupId, comment.CreatorName, comment.Content, comment.Photo)
+	respFromDb, err := stmt.Exec(comment.PostId, comment.UserId, comment.GroupId, comment.Content, comment.Photo, comment.ParentId)
 	if err != nil {
-		return structs.GroupPost{}, err
+		return structs.GroupPost{}, 0, err
 	}
 
-	var updatedGroupPost structs.GroupPost
-	err = DB.QueryRow(`
-		SELECT * FROM group_posts
-		WHERE id = ? AND group_id = ?
-	`, comment.PostId, comment.GroupId).Scan(&updatedGroupPost.Id, &updatedGroupPost.UserId, &updatedGroupPost.GroupId, &updatedGroupPost.ProfilePicture, &updatedGroupPost.Title, &updatedGroupPost.Content, &updatedGroupPost.Photo)
+	commentId, err := respFromDb.LastInsertId()
 	if err != nil {
-		return structs.GroupPost{}, err
+		return structs.GroupPost{}, 0, err
+	}
+
+	updatedGroupPost, err := GetGroupPostById(comment.PostId)
+	if err != nil {
+		return structs.GroupPost{}, 0, err
+	}
+	if updatedGroupPost == nil || updatedGroupPost.GroupId != comment.GroupId {
+		return structs.GroupPost{}, 0, sql.ErrNoRows
 	}
 	updatedGroupPost.Comments, err = ReadAllGroupComments(comment.PostId, comment.GroupId)
 	if err != nil {
-		return structs.GroupPost{}, err
+		return structs.GroupPost{}, 0, err
 	}
 
-	return updatedGroupPost, nil
+	return *updatedGroupPost, int(commentId), nil
 }
 
+// ReadAllGroupComments returns the comments of a group post as a tree, in the same order as ReadAllComments
 func ReadAllGroupComments(postId, groupId int) ([]structs.GroupComment, error) {
-	comments := make([]structs.GroupComment, 0)
 	rows, err := DB.Query(`
-		SELECT *
-		FROM group_comments
-		WHERE post_id = ? AND group_id = ?
-		ORDER BY id DESC
+		SELECT c.id, c.post_id, c.user_id, c.group_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM group_comment_reactions cr WHERE cr.comment_id = c.id), c.hidden
+		FROM group_comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.post_id = ? AND c.group_id = ?
+		ORDER BY c.id
 	`, postId, groupId)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	ids := make(map[int]bool)
+	children := make(map[int][]structs.GroupComment)
 	for rows.Next() {
 		var comment structs.GroupComment
-		err := rows.Scan(&comment.Id, &comment.UserId, &comment.PostId, &comment.GroupId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo)
+		var hidden bool
+		err := rows.Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.GroupId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.Reactions, &hidden)
 		if err != nil {
 			return nil, err
 		}
-		comments = append(comments, comment)
+		ids[comment.Id] = true
+		// a hidden comment is left out of the tree and takes its replies with it
+		if hidden {
+			continue
+		}
+		parentId := comment.ParentId
+		if !ids[parentId] {
+			parentId = 0
+		}
+		children[parentId] = append(children[parentId], comment)
 	}
-	if len(comments) == 0 {
-		return comments, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	comments := nestGroupComments(0, children)
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	if comments == nil {
+		return make([]structs.GroupComment, 0), nil
+	}
 	return comments, nil
 }
 
+func nestGroupComments(parentId int, children map[int][]structs.GroupComment) []structs.GroupComment {
+	comments := children[parentId]
+	for i := range comments {
+		comments[i].Replies = nestGroupComments(comments[i].Id, children)
+		comments[i].ReplyCount = len(comments[i].Replies)
+	}
+	return comments
+}
+
 func ReadAllGroupEvents(userId, groupId int) ([]structs.Event, error) {
 	events := make([]structs.Event, 0)
 	rows, err := DB.Query(`
@@ -950,11 +1111,15 @@ func GetUserIdByPrivateChatId(privateChatId int) (int, int, error) {
 	return user1Id, user2Id, nil
 }
 
+// GetUserIdByGroupChatId lists the members the group chat is sent to, banned users never get it
 func GetUserIdByGroupChatId(groupChatId int) ([]int, error) {
 	rows, err := DB.Query(`
-		SELECT requester_id FROM group_members
-		WHERE group_id = ?
-	`, groupChatId)
+		SELECT gm.requester_id FROM group_members gm
+		WHERE gm.group_id = ? AND NOT EXISTS(
+			SELECT 1 FROM group_bans gb
+			WHERE gb.group_id = gm.group_id AND gb.user_id = gm.requester_id AND (gb.expires_at IS NULL OR gb.expires_at > ?)
+		)
+	`, groupChatId, time.Now().UTC())
 
 	if err != nil {
 		return nil, err
@@ -1280,7 +1445,7 @@ func GetPrivateChats(userId int) ([]structs.DisplayChat, error) {
 			users u2 ON u2.id = pc.user2_id 
 		LEFT JOIN 
 			chat_messages cm ON cm.id = (
-				SELECT MAX(id) FROM chat_messages WHERE private_chat_id = pc.id
+				SELECT MAX(id) FROM chat_messages WHERE private_chat_id = pc.id AND hidden = 0
 			)
 		WHERE 
 			pc.user1_id = ? OR pc.user2_id = ?
@@ -1329,7 +1494,7 @@ func GetGroupChats(userId int) ([]structs.DisplayChat, error) {
 			groups g ON g.id = gm.group_id
 		LEFT JOIN 
 			chat_messages cm ON cm.id = (
-				SELECT MAX(id) FROM chat_messages WHERE group_chat_id = gm.group_id
+				SELECT MAX(id) FROM chat_messages WHERE group_chat_id = gm.group_id AND hidden = 0
 			)
 		WHERE 
 			gm.requester_id = ?
@@ -1403,14 +1568,14 @@ func GetMessagesForUser(chatId int, chatType string) ([]structs.ChatMessage, err
 			SELECT m.id, m.sender_id, m.content, m.created_at, m.private_chat_id, u.avatar
 			FROM chat_messages m
 			INNER JOIN users u ON m.sender_id = u.id
-			WHERE m.private_chat_id = ?
+			WHERE m.private_chat_id = ? AND m.hidden = 0
 		`
 	} else if chatType == "group" {
 		query = `
 			SELECT m.id, m.sender_id, m.content, m.created_at, m.group_chat_id, u.avatar
 			FROM chat_messages m
 			INNER JOIN users u ON m.sender_id = u.id
-			WHERE m.group_chat_id = ?
+			WHERE m.group_chat_id = ? AND m.hidden = 0
 		`
 	} else {
 		return nil, errors.New("invalid chat type")
@@ -1501,15 +1666,15 @@ func SearchFollowers(query string, loggedInUserId int) ([]structs.User, error) {
 	return users, nil
 }
 
-func SearchGroup(query string) ([]structs.Group, error) {
+func SearchGroup(query string, viewerId int) ([]structs.Group, error) {
 	query = strings.ToLower(query)
 	searchQuery := query + "%"
 
 	rows, err := DB.Query(`
-		SELECT id, name
-		FROM groups
-		WHERE LOWER(name) LIKE ?
-	`, searchQuery)
+		SELECT g.id, g.name, g.visibility
+		FROM groups g
+		WHERE LOWER(g.name) LIKE ? AND `+groupListedCondition+`
+	`, searchQuery, viewerId)
 	if err != nil {
 		return nil, err
 	}
@@ -1518,7 +1683,7 @@ func SearchGroup(query string) ([]structs.Group, error) {
 	var groups []structs.Group
 	for rows.Next() {
 		var group structs.Group
-		if err := rows.Scan(&group.Id, &group.Name); err != nil {
+		if err := rows.Scan(&group.Id, &group.Name, &group.Visibility); err != nil {
 			return nil, err
 		}
 		groups = append(groups, group)
@@ -1637,3 +1802,2715 @@ func InsertGroupNotificaton(notification structs.Notification) (structs.Notifica
 
 	return notification, nil
 }
+
+// TAGS
+
+// postVisibilityCondition limits posts aliased as p to the ones the viewer is allowed to see.
+// It takes the viewer id three times: own posts, followers-only posts and custom audience lists.
+const postVisibilityCondition = `(
+	p.user_id = ? OR
+	p.privacy = 'public' OR
+	(p.privacy = 'private' AND EXISTS(SELECT 1 FROM user_following WHERE follower_id = ? AND following_id = p.user_id)) OR
+	(',' || p.privacy || ',') LIKE '%,' || ? || ',%'
+)`
+
+func InsertPostTags(postId int, tags []string) error {
+	for _, tag := range tags {
+		_, err := DB.Exec(`
+			INSERT INTO post_tags (tag, post_id)
+			VALUES (?, ?)
+		`, tag, postId)
+		if err != nil {
+			return fmt.Errorf("error inserting post tag: %v", err)
+		}
+	}
+	return nil
+}
+
+func InsertGroupPostTags(groupPostId, groupId int, tags []string) error {
+	for _, tag := range tags {
+		_, err := DB.Exec(`
+			INSERT INTO group_post_tags (tag, group_post_id, group_id)
+			VALUES (?, ?, ?)
+		`, tag, groupPostId, groupId)
+		if err != nil {
+			return fmt.Errorf("error inserting group post tag: %v", err)
+		}
+	}
+	return nil
+}
+
+func ReadPostsByTag(tag string, userId, limit, offset int) ([]structs.Post, error) {
+	posts := make([]structs.Post, 0)
+	rows, err := DB.Query(`
+		SELECT DISTINCT p.id, p.user_id, COALESCE(u.avatar, ''), p.title, p.content, p.photo, p.privacy, p.repost_of
+		FROM posts p
+		LEFT JOIN users u ON u.id = p.user_id
+		JOIN post_tags pt ON pt.post_id = p.id
+		WHERE pt.tag = ? AND p.status = 'published' AND `+postVisibilityCondition+`
+		ORDER BY p.id DESC
+		LIMIT ? OFFSET ?
+	`, tag, userId, userId, userId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tagged posts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post structs.Post
+		if err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy, &post.RepostOf); err != nil {
+			return nil, fmt.Errorf("error scanning tagged post: %v", err)
+		}
+		if post.Privacy != "public" && post.Privacy != "private" {
+			post.Privacy = "private"
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range posts {
+		posts[i].Comments, err = ReadAllComments(posts[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		if err := attachRepostInfo(&posts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return posts, nil
+}
+
+func ReadGroupPostsByTag(tag string, userId, limit, offset int) ([]structs.GroupPost, error) {
+	posts := make([]structs.GroupPost, 0)
+	rows, err := DB.Query(`
+		SELECT DISTINCT gp.id, gp.group_id, gp.user_id, COALESCE(u.avatar, ''), gp.title, gp.content, gp.photo
+		FROM group_posts gp
+		LEFT JOIN users u ON u.id = gp.user_id
+		JOIN group_post_tags gpt ON gpt.group_post_id = gp.id
+		JOIN groups g ON g.id = gp.group_id
+		WHERE gpt.tag = ? AND gp.status = 'published' AND `+groupReadableCondition+`
+		ORDER BY gp.id DESC
+		LIMIT ? OFFSET ?
+	`, tag, userId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tagged group posts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post structs.GroupPost
+		if err := rows.Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo); err != nil {
+			return nil, fmt.Errorf("error scanning tagged group post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range posts {
+		posts[i].Comments, err = ReadAllGroupComments(posts[i].Id, posts[i].GroupId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return posts, nil
+}
+
+// ReadTrendingTags counts tag usage on public posts created within the last given hours
+func ReadTrendingTags(hours, limit int) ([]structs.TrendingTag, error) {
+	tags := make([]structs.TrendingTag, 0)
+	rows, err := DB.Query(`
+		SELECT pt.tag, COUNT(*) AS uses
+		FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id
+		WHERE p.privacy = 'public' AND pt.created_at >= datetime('now', ?)
+		GROUP BY pt.tag
+		ORDER BY uses DESC, pt.tag ASC
+		LIMIT ?
+	`, fmt.Sprintf("-%d hours", hours), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying trending tags: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag structs.TrendingTag
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("error scanning trending tag: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func FollowTag(userId int, tag string) error {
+	_, err := DB.Exec(`
+		INSERT OR IGNORE INTO tag_followers (user_id, tag)
+		VALUES (?, ?)
+	`, userId, tag)
+	if err != nil {
+		return fmt.Errorf("error following tag: %v", err)
+	}
+	return nil
+}
+
+func UnfollowTag(userId int, tag string) error {
+	_, err := DB.Exec(`
+		DELETE FROM tag_followers
+		WHERE user_id = ? AND tag = ?
+	`, userId, tag)
+	if err != nil {
+		return fmt.Errorf("error unfollowing tag: %v", err)
+	}
+	return nil
+}
+
+func IsFollowingTag(userId int, tag string) (bool, error) {
+	var exists int
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM tag_followers WHERE user_id = ? AND tag = ?)
+	`, userId, tag).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists == 1, nil
+}
+
+func GetFollowedTags(userId int) ([]string, error) {
+	tags := make([]string, 0)
+	rows, err := DB.Query(`
+		SELECT tag FROM tag_followers
+		WHERE user_id = ?
+		ORDER BY tag ASC
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// MENTIONS
+
+// IsPostVisibleToUser applies the same audience rules as the feed to a single post
+func IsPostVisibleToUser(postId, userId int) (bool, error) {
+	var exists int
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM posts p WHERE p.id = ? AND p.status = 'published' AND `+postVisibilityCondition+`)
+	`, postId, userId, userId, userId).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists == 1, nil
+}
+
+// GetUserIdByMention resolves a mention to a user id, numeric mentions are treated as ids and
+// everything else as a case-insensitive nickname. It returns 0 when nobody matches.
+func GetUserIdByMention(mention string) (int, error) {
+	var userId int
+	var err error
+	if id, convErr := strconv.Atoi(mention); convErr == nil {
+		err = DB.QueryRow(`
+			SELECT id FROM users WHERE id = ?
+		`, id).Scan(&userId)
+	} else {
+		err = DB.QueryRow(`
+			SELECT id FROM users WHERE LOWER(nickname) = ?
+		`, strings.ToLower(mention)).Scan(&userId)
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return userId, nil
+}
+
+func InsertMention(mention structs.Mention) error {
+	_, err := DB.Exec(`
+		INSERT INTO mentions (user_id, sender_id, content_type, content_id, post_id, group_id, private_chat_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, mention.UserId, mention.SenderId, mention.ContentType, mention.ContentId, mention.PostId, mention.GroupId, mention.PrivateChatId)
+	if err != nil {
+		return fmt.Errorf("error inserting mention: %v", err)
+	}
+	return nil
+}
+
+// REPOSTS
+
+// GetPostById returns the post without comments, or nil if it doesn't exist. Privacy is returned as stored.
+func GetPostById(postId int) (*structs.Post, error) {
+	var post structs.Post
+	err := DB.QueryRow(`
+		SELECT p.id, p.user_id, COALESCE(u.avatar, ''), p.title, p.content, p.photo, p.privacy, p.repost_of, p.status, p.publish_at, p.pinned_at IS NOT NULL
+		FROM posts p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.id = ?
+	`, postId).Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy, &post.RepostOf, &post.Status, &post.PublishAt, &post.Pinned)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// attachRepostInfo fills in the repost count and, for reposts, the original post or a tombstone if it was deleted
+func attachRepostInfo(post *structs.Post) error {
+	err := DB.QueryRow(`
+		SELECT COUNT(*) FROM posts WHERE repost_of = ? AND status = 'published'
+	`, post.Id).Scan(&post.RepostCount)
+	if err != nil {
+		return err
+	}
+
+	if post.RepostOf == 0 {
+		return nil
+	}
+	original, err := GetPostById(post.RepostOf)
+	if err != nil {
+		return err
+	}
+	if original == nil || original.Privacy != "public" || original.Status != "published" {
+		post.OriginalDeleted = true
+		return nil
+	}
+	post.Original = original
+	return nil
+}
+
+func HasUserReposted(userId, postId int) (bool, error) {
+	var exists int
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM posts WHERE user_id = ? AND repost_of = ? AND content = '')
+	`, userId, postId).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists == 1, nil
+}
+
+// DeletePost removes the post with its comments, tags and poll. Reposts keep pointing at the id and show up as tombstones.
+func DeletePost(postId int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM comment_reactions WHERE comment_id IN (SELECT id FROM comments WHERE post_id = ?)`,
+		`DELETE FROM comments WHERE post_id = ?`,
+		`DELETE FROM post_tags WHERE post_id = ?`,
+		`DELETE FROM mentions WHERE post_id = ? AND content_type IN ('post', 'comment')`,
+		`DELETE FROM poll_votes WHERE poll_id IN (SELECT id FROM polls WHERE post_id = ?)`,
+		`DELETE FROM poll_options WHERE poll_id IN (SELECT id FROM polls WHERE post_id = ?)`,
+		`DELETE FROM polls WHERE post_id = ?`,
+		`DELETE FROM bookmarks WHERE post_id = ?`,
+		`DELETE FROM posts WHERE id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, postId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error deleting post: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// BOOKMARKS
+func CreateBookmarkCollection(userId int, name string) (structs.BookmarkCollection, error) {
+	respFromDb, err := DB.Exec(`
+		INSERT INTO bookmark_collections (user_id, name)
+		VALUES (?, ?)
+	`, userId, name)
+	if err != nil {
+		return structs.BookmarkCollection{}, fmt.Errorf("error creating bookmark collection: %v", err)
+	}
+
+	id, err := respFromDb.LastInsertId()
+	if err != nil {
+		return structs.BookmarkCollection{}, err
+	}
+
+	return structs.BookmarkCollection{Id: int(id), UserId: userId, Name: name}, nil
+}
+
+func GetBookmarkCollections(userId int) ([]structs.BookmarkCollection, error) {
+	collections := make([]structs.BookmarkCollection, 0)
+	rows, err := DB.Query(`
+		SELECT bc.id, bc.user_id, bc.name, COUNT(b.id)
+		FROM bookmark_collections bc
+		LEFT JOIN bookmarks b ON b.collection_id = bc.id
+		WHERE bc.user_id = ?
+		GROUP BY bc.id
+		ORDER BY bc.id ASC
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying bookmark collections: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var collection structs.BookmarkCollection
+		if err := rows.Scan(&collection.Id, &collection.UserId, &collection.Name, &collection.Bookmarks); err != nil {
+			return nil, fmt.Errorf("error scanning bookmark collection: %v", err)
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+func GetBookmarkCollection(collectionId int) (*structs.BookmarkCollection, error) {
+	var collection structs.BookmarkCollection
+	err := DB.QueryRow(`
+		SELECT id, user_id, name FROM bookmark_collections
+		WHERE id = ?
+	`, collectionId).Scan(&collection.Id, &collection.UserId, &collection.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// GetOrCreateDefaultCollection returns the user's "Saved" collection, used when a bookmark has no collection
+func GetOrCreateDefaultCollection(userId int) (int, error) {
+	var collectionId int
+	err := DB.QueryRow(`
+		SELECT id FROM bookmark_collections
+		WHERE user_id = ? AND name = 'Saved'
+		ORDER BY id ASC
+		LIMIT 1
+	`, userId).Scan(&collectionId)
+	if err == sql.ErrNoRows {
+		collection, err := CreateBookmarkCollection(userId, "Saved")
+		if err != nil {
+			return 0, err
+		}
+		return collection.Id, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return collectionId, nil
+}
+
+func DeleteBookmarkCollection(collectionId int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM bookmarks WHERE collection_id = ?
+	`, collectionId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM bookmark_collections WHERE id = ?
+	`, collectionId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func CheckBookmarkIfExists(collectionId, postId, groupPostId int) (bool, error) {
+	var exists int
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM bookmarks WHERE collection_id = ? AND post_id = ? AND group_post_id = ?)
+	`, collectionId, postId, groupPostId).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists == 1, nil
+}
+
+// InsertBookmark adds the bookmark at the end of its collection
+func InsertBookmark(bookmark structs.Bookmark) (structs.Bookmark, error) {
+	err := DB.QueryRow(`
+		SELECT COALESCE(MAX(position), 0) + 1 FROM bookmarks
+		WHERE collection_id = ?
+	`, bookmark.CollectionId).Scan(&bookmark.Position)
+	if err != nil {
+		return structs.Bookmark{}, err
+	}
+
+	respFromDb, err := DB.Exec(`
+		INSERT INTO bookmarks (user_id, collection_id, post_id, group_post_id, position)
+		VALUES (?, ?, ?, ?, ?)
+	`, bookmark.UserId, bookmark.CollectionId, bookmark.PostId, bookmark.GroupPostId, bookmark.Position)
+	if err != nil {
+		return structs.Bookmark{}, fmt.Errorf("error inserting bookmark: %v", err)
+	}
+
+	id, err := respFromDb.LastInsertId()
+	if err != nil {
+		return structs.Bookmark{}, err
+	}
+	bookmark.Id = int(id)
+
+	return bookmark, nil
+}
+
+func GetBookmarkById(bookmarkId int) (*structs.Bookmark, error) {
+	var bookmark structs.Bookmark
+	err := DB.QueryRow(`
+		SELECT id, user_id, collection_id, post_id, group_post_id, position
+		FROM bookmarks
+		WHERE id = ?
+	`, bookmarkId).Scan(&bookmark.Id, &bookmark.UserId, &bookmark.CollectionId, &bookmark.PostId, &bookmark.GroupPostId, &bookmark.Position)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func DeleteBookmark(bookmarkId int) error {
+	_, err := DB.Exec(`
+		DELETE FROM bookmarks WHERE id = ?
+	`, bookmarkId)
+	return err
+}
+
+// ReadBookmarks returns the bookmarks of a collection in their saved order, without checking who can see them
+func ReadBookmarks(collectionId int) ([]structs.Bookmark, error) {
+	bookmarks := make([]structs.Bookmark, 0)
+	rows, err := DB.Query(`
+		SELECT id, user_id, collection_id, post_id, group_post_id, position
+		FROM bookmarks
+		WHERE collection_id = ?
+		ORDER BY position ASC, id ASC
+	`, collectionId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying bookmarks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookmark structs.Bookmark
+		if err := rows.Scan(&bookmark.Id, &bookmark.UserId, &bookmark.CollectionId, &bookmark.PostId, &bookmark.GroupPostId, &bookmark.Position); err != nil {
+			return nil, fmt.Errorf("error scanning bookmark: %v", err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+// ReorderBookmarks sets the position of each bookmark to its index in bookmarkIds.
+// bookmarkIds has to list every bookmark of the collection exactly once, otherwise nothing is changed.
+func ReorderBookmarks(collectionId int, bookmarkIds []int) error {
+	listed := make(map[int]bool, len(bookmarkIds))
+	for _, bookmarkId := range bookmarkIds {
+		if listed[bookmarkId] {
+			return fmt.Errorf("bookmark %d is listed more than once", bookmarkId)
+		}
+		listed[bookmarkId] = true
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM bookmarks WHERE collection_id = ?
+	`, collectionId).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count != len(bookmarkIds) {
+		tx.Rollback()
+		return fmt.Errorf("list all %d bookmarks of the collection in their new order", count)
+	}
+
+	for position, bookmarkId := range bookmarkIds {
+		result, err := tx.Exec(`
+			UPDATE bookmarks SET position = ?
+			WHERE id = ? AND collection_id = ?
+		`, position+1, bookmarkId, collectionId)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			tx.Rollback()
+			return fmt.Errorf("bookmark %d is not in this collection", bookmarkId)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetGroupPostById returns the group post without comments, or nil if it doesn't exist
+func GetGroupPostById(postId int) (*structs.GroupPost, error) {
+	var post structs.GroupPost
+	err := DB.QueryRow(`
+		SELECT gp.id, gp.group_id, gp.user_id, COALESCE(u.avatar, ''), gp.title, gp.content, gp.photo, gp.status, gp.publish_at, gp.pinned_at IS NOT NULL
+		FROM group_posts gp
+		LEFT JOIN users u ON u.id = gp.user_id
+		WHERE gp.id = ?
+	`, postId).Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Status, &post.PublishAt, &post.Pinned)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// SCHEDULED POSTS
+
+// GetUnpublishedPosts returns the user's drafts and scheduled posts, the next to go out first
+func GetUnpublishedPosts(userId int) ([]structs.Post, error) {
+	posts := make([]structs.Post, 0)
+	rows, err := DB.Query(`
+		SELECT p.id, p.user_id, COALESCE(u.avatar, ''), p.title, p.content, p.photo, p.privacy, p.repost_of, p.status, p.publish_at
+		FROM posts p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.user_id = ? AND p.status IN ('draft', 'scheduled')
+		ORDER BY p.publish_at IS NULL, p.publish_at ASC, p.id DESC
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unpublished posts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post structs.Post
+		if err := rows.Scan(&post.Id, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Privacy, &post.RepostOf, &post.Status, &post.PublishAt); err != nil {
+			return nil, fmt.Errorf("error scanning unpublished post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func GetUnpublishedGroupPosts(userId int) ([]structs.GroupPost, error) {
+	posts := make([]structs.GroupPost, 0)
+	rows, err := DB.Query(`
+		SELECT gp.id, gp.group_id, gp.user_id, COALESCE(u.avatar, ''), gp.title, gp.content, gp.photo, gp.status, gp.publish_at
+		FROM group_posts gp
+		LEFT JOIN users u ON u.id = gp.user_id
+		WHERE gp.user_id = ? AND gp.status IN ('draft', 'scheduled')
+		ORDER BY gp.publish_at IS NULL, gp.publish_at ASC, gp.id DESC
+	`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unpublished group posts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post structs.GroupPost
+		if err := rows.Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Status, &post.PublishAt); err != nil {
+			return nil, fmt.Errorf("error scanning unpublished group post: %v", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// UpdateUnpublishedPost saves changes to a draft or scheduled post and reports whether it was still unpublished
+func UpdateUnpublishedPost(post structs.Post) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE posts
+		SET title = ?, content = ?, photo = ?, privacy = ?, status = ?, publish_at = ?
+		WHERE id = ? AND status IN ('draft', 'scheduled')
+	`, post.Title, post.Content, post.Photo, post.Privacy, post.Status, post.PublishAt, post.Id)
+	if err != nil {
+		return false, fmt.Errorf("error updating post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func UpdateUnpublishedGroupPost(post structs.GroupPost) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE group_posts
+		SET title = ?, content = ?, photo = ?, status = ?, publish_at = ?
+		WHERE id = ? AND status IN ('draft', 'scheduled')
+	`, post.Title, post.Content, post.Photo, post.Status, post.PublishAt, post.Id)
+	if err != nil {
+		return false, fmt.Errorf("error updating group post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetDueScheduledPostIds returns the ids of scheduled posts whose publish time has passed
+func GetDueScheduledPostIds(now time.Time) ([]int, error) {
+	return queryIds(`
+		SELECT id FROM posts
+		WHERE status = 'scheduled' AND publish_at <= ?
+	`, now.UTC())
+}
+
+// GetDueScheduledGroupPostIds leaves out archived groups, their scheduled posts go out once they are unarchived
+func GetDueScheduledGroupPostIds(now time.Time) ([]int, error) {
+	return queryIds(`
+		SELECT id FROM group_posts
+		WHERE status = 'scheduled' AND publish_at <= ?
+		AND group_id NOT IN (SELECT id FROM groups WHERE archived_at IS NOT NULL)
+	`, now.UTC())
+}
+
+// PublishPost marks the scheduled post as published and reports whether it was still scheduled,
+// so a post edited, published by hand or hidden while the publisher runs isn't announced
+func PublishPost(postId int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE posts SET status = 'published'
+		WHERE id = ? AND status = 'scheduled'
+	`, postId)
+	if err != nil {
+		return false, fmt.Errorf("error publishing post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// PublishGroupPost moves the scheduled group post to the given status, "published" or "pending" when the group
+// approves posts first
+func PublishGroupPost(postId int, status string) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE group_posts SET status = ?
+		WHERE id = ? AND status = 'scheduled'
+	`, status, postId)
+	if err != nil {
+		return false, fmt.Errorf("error publishing group post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func queryIds(query string, args ...interface{}) ([]int, error) {
+	ids := make([]int, 0)
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// POLLS
+
+var ErrAlreadyVoted = errors.New("You have already voted in this poll")
+
+// InsertPoll stores the poll of a post together with its options in the given order
+func InsertPoll(poll structs.Poll) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	respFromDb, err := tx.Exec(`
+		INSERT INTO polls (post_id, multiple_choice, show_results, closes_at)
+		VALUES (?, ?, ?, ?)
+	`, poll.PostId, poll.MultipleChoice, poll.ShowResults, poll.ClosesAt)
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("error inserting poll: %v", err)
+	}
+	pollId, err := respFromDb.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for i, option := range poll.Options {
+		_, err := tx.Exec(`
+			INSERT INTO poll_options (poll_id, position, text)
+			VALUES (?, ?, ?)
+		`, pollId, i, option.Text)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("error inserting poll option: %v", err)
+		}
+	}
+
+	return int(pollId), tx.Commit()
+}
+
+// GetPollByPostId returns the poll with vote counts and the user's own choices, or nil if the post has no poll.
+// Hiding the results is left to the caller.
+func GetPollByPostId(postId, userId int) (*structs.Poll, error) {
+	var poll structs.Poll
+	err := DB.QueryRow(`
+		SELECT id, post_id, multiple_choice, show_results, closes_at
+		FROM polls
+		WHERE post_id = ?
+	`, postId).Scan(&poll.Id, &poll.PostId, &poll.MultipleChoice, &poll.ShowResults, &poll.ClosesAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting poll: %v", err)
+	}
+
+	rows, err := DB.Query(`
+		SELECT o.id, o.text, COUNT(v.user_id),
+			COALESCE(MAX(v.user_id = ?), 0)
+		FROM poll_options o
+		LEFT JOIN poll_votes v ON v.option_id = o.id
+		WHERE o.poll_id = ?
+		GROUP BY o.id
+		ORDER BY o.position
+	`, userId, poll.Id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting poll options: %v", err)
+	}
+	defer rows.Close()
+
+	poll.Options = make([]structs.PollOption, 0)
+	for rows.Next() {
+		var option structs.PollOption
+		if err := rows.Scan(&option.Id, &option.Text, &option.Votes, &option.Chosen); err != nil {
+			return nil, fmt.Errorf("error scanning poll option: %v", err)
+		}
+		if option.Chosen {
+			poll.HasVoted = true
+		}
+		poll.Options = append(poll.Options, option)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = DB.QueryRow(`
+		SELECT COUNT(DISTINCT user_id) FROM poll_votes WHERE poll_id = ?
+	`, poll.Id).Scan(&poll.TotalVoters)
+	if err != nil {
+		return nil, fmt.Errorf("error counting poll voters: %v", err)
+	}
+
+	return &poll, nil
+}
+
+// InsertPollVotes records the user's choices. A user votes once per poll,
+// so ErrAlreadyVoted is returned if they have any vote on it already.
+func InsertPollVotes(pollId, userId int, optionIds []int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var hasVoted int
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM poll_votes WHERE poll_id = ? AND user_id = ?)
+	`, pollId, userId).Scan(&hasVoted)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error checking poll vote: %v", err)
+	}
+	if hasVoted == 1 {
+		tx.Rollback()
+		return ErrAlreadyVoted
+	}
+
+	for _, optionId := range optionIds {
+		_, err := tx.Exec(`
+			INSERT INTO poll_votes (poll_id, option_id, user_id)
+			VALUES (?, ?, ?)
+		`, pollId, optionId, userId)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error inserting poll vote: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// COMMENT REPLIES
+
+// GetCommentById returns the comment with its reply and reaction counts but without the replies, or nil if it doesn't exist or was hidden
+func GetCommentById(commentId int) (*structs.Comment, error) {
+	return getCommentById(commentId, false)
+}
+
+// GetCommentByIdIncludingHidden is GetCommentById for moderation, it also finds comments that were hidden
+func GetCommentByIdIncludingHidden(commentId int) (*structs.Comment, error) {
+	return getCommentById(commentId, true)
+}
+
+func getCommentById(commentId int, includeHidden bool) (*structs.Comment, error) {
+	var comment structs.Comment
+	err := DB.QueryRow(`
+		SELECT c.id, c.post_id, c.user_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND r.hidden = 0),
+			(SELECT COUNT(*) FROM comment_reactions cr WHERE cr.comment_id = c.id)
+		FROM comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id = ? AND (? OR c.hidden = 0)
+	`, commentId, includeHidden).Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.ReplyCount, &comment.Reactions)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func GetGroupCommentById(commentId int) (*structs.GroupComment, error) {
+	return getGroupCommentById(commentId, false)
+}
+
+func GetGroupCommentByIdIncludingHidden(commentId int) (*structs.GroupComment, error) {
+	return getGroupCommentById(commentId, true)
+}
+
+func getGroupCommentById(commentId int, includeHidden bool) (*structs.GroupComment, error) {
+	var comment structs.GroupComment
+	err := DB.QueryRow(`
+		SELECT c.id, c.post_id, c.user_id, c.group_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM group_comments r WHERE r.parent_id = c.id AND r.hidden = 0),
+			(SELECT COUNT(*) FROM group_comment_reactions cr WHERE cr.comment_id = c.id)
+		FROM group_comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.id = ? AND (? OR c.hidden = 0)
+	`, commentId, includeHidden).Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.GroupId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.ReplyCount, &comment.Reactions)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting group comment: %v", err)
+	}
+	return &comment, nil
+}
+
+// GetCommentDepth returns how many replies deep the comment is, top level comments are at depth 0
+func GetCommentDepth(commentId int) (int, error) {
+	return commentDepth("comments", commentId)
+}
+
+func GetGroupCommentDepth(commentId int) (int, error) {
+	return commentDepth("group_comments", commentId)
+}
+
+func commentDepth(table string, commentId int) (int, error) {
+	var depth int
+	err := DB.QueryRow(`
+		WITH RECURSIVE ancestors (id, parent_id) AS (
+			SELECT id, parent_id FROM `+table+` WHERE id = ?
+			UNION ALL
+			SELECT c.id, c.parent_id FROM `+table+` c
+			JOIN ancestors a ON c.id = a.parent_id
+		)
+		SELECT COUNT(*) - 1 FROM ancestors
+	`, commentId).Scan(&depth)
+	if err != nil {
+		return 0, fmt.Errorf("error getting comment depth: %v", err)
+	}
+	return depth, nil
+}
+
+// commentOrder maps the sort option of comment reads to an ORDER BY clause
+var commentOrder = map[string]string{
+	"oldest":       "c.id",
+	"newest":       "c.id DESC",
+	"most_reacted": "reactions DESC, c.id DESC",
+}
+
+// ReadCommentsPage returns one page of the comments of a post that reply to parentId, top level comments have parentId 0.
+// Deeper replies are not included, only counted, so they can be loaded the same way.
+func ReadCommentsPage(postId, parentId int, sort string, limit, offset int) ([]structs.Comment, error) {
+	order, ok := commentOrder[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid comment sort: %s", sort)
+	}
+	rows, err := DB.Query(`
+		SELECT c.id, c.post_id, c.user_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND r.hidden = 0),
+			(SELECT COUNT(*) FROM comment_reactions cr WHERE cr.comment_id = c.id) AS reactions
+		FROM comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.post_id = ? AND c.parent_id = ? AND c.hidden = 0
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, postId, parentId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error reading comments: %v", err)
+	}
+	defer rows.Close()
+
+	comments := make([]structs.Comment, 0)
+	for rows.Next() {
+		var comment structs.Comment
+		err := rows.Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.ReplyCount, &comment.Reactions)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning comment: %v", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func ReadGroupCommentsPage(postId, groupId, parentId int, sort string, limit, offset int) ([]structs.GroupComment, error) {
+	order, ok := commentOrder[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid comment sort: %s", sort)
+	}
+	rows, err := DB.Query(`
+		SELECT c.id, c.post_id, c.user_id, c.group_id, COALESCE(u.avatar, ''), `+authorNameColumn+`, c.content, c.photo, c.parent_id, c.created_at, c.edited_at,
+			(SELECT COUNT(*) FROM group_comments r WHERE r.parent_id = c.id AND r.hidden = 0),
+			(SELECT COUNT(*) FROM group_comment_reactions cr WHERE cr.comment_id = c.id) AS reactions
+		FROM group_comments c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.post_id = ? AND c.group_id = ? AND c.parent_id = ? AND c.hidden = 0
+		ORDER BY `+order+`
+		LIMIT ? OFFSET ?
+	`, postId, groupId, parentId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error reading group comments: %v", err)
+	}
+	defer rows.Close()
+
+	comments := make([]structs.GroupComment, 0)
+	for rows.Next() {
+		var comment structs.GroupComment
+		err := rows.Scan(&comment.Id, &comment.PostId, &comment.UserId, &comment.GroupId, &comment.ProfilePicture, &comment.CreatorName, &comment.Content, &comment.Photo, &comment.ParentId, &comment.CreatedAt, &comment.EditedAt, &comment.ReplyCount, &comment.Reactions)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning group comment: %v", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// COMMENT EDITING AND REACTIONS
+
+// UpdateComment saves the new content of a comment and marks it as edited
+func UpdateComment(commentId int, content, photo string) error {
+	_, err := DB.Exec(`
+		UPDATE comments SET content = ?, photo = ?, edited_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, content, photo, commentId)
+	if err != nil {
+		return fmt.Errorf("error updating comment: %v", err)
+	}
+	return nil
+}
+
+func UpdateGroupComment(commentId int, content, photo string) error {
+	_, err := DB.Exec(`
+		UPDATE group_comments SET content = ?, photo = ?, edited_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, content, photo, commentId)
+	if err != nil {
+		return fmt.Errorf("error updating group comment: %v", err)
+	}
+	return nil
+}
+
+// SetCommentReaction adds the user's reaction to a comment or replaces the one they gave before
+func SetCommentReaction(commentId, userId int, reaction string) error {
+	_, err := DB.Exec(`
+		INSERT INTO comment_reactions (comment_id, user_id, reaction)
+		VALUES (?, ?, ?)
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET reaction = excluded.reaction
+	`, commentId, userId, reaction)
+	if err != nil {
+		return fmt.Errorf("error saving comment reaction: %v", err)
+	}
+	return nil
+}
+
+func SetGroupCommentReaction(commentId, userId int, reaction string) error {
+	_, err := DB.Exec(`
+		INSERT INTO group_comment_reactions (comment_id, user_id, reaction)
+		VALUES (?, ?, ?)
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET reaction = excluded.reaction
+	`, commentId, userId, reaction)
+	if err != nil {
+		return fmt.Errorf("error saving group comment reaction: %v", err)
+	}
+	return nil
+}
+
+func DeleteCommentReaction(commentId, userId int) error {
+	_, err := DB.Exec(`
+		DELETE FROM comment_reactions WHERE comment_id = ? AND user_id = ?
+	`, commentId, userId)
+	if err != nil {
+		return fmt.Errorf("error deleting comment reaction: %v", err)
+	}
+	return nil
+}
+
+func DeleteGroupCommentReaction(commentId, userId int) error {
+	_, err := DB.Exec(`
+		DELETE FROM group_comment_reactions WHERE comment_id = ? AND user_id = ?
+	`, commentId, userId)
+	if err != nil {
+		return fmt.Errorf("error deleting group comment reaction: %v", err)
+	}
+	return nil
+}
+
+// LINK PREVIEWS
+
+// GetLinkPreview returns the cached preview of the url and when it was fetched, or nil if it isn't cached.
+// Failed fetches are cached too, as previews without a title or image.
+func GetLinkPreview(url string) (*structs.LinkPreview, time.Time, error) {
+	var preview structs.LinkPreview
+	var fetchedAt time.Time
+	err := DB.QueryRow(`
+		SELECT url, title, description, image, site_name, fetched_at
+		FROM link_previews
+		WHERE url = ?
+	`, url).Scan(&preview.Url, &preview.Title, &preview.Description, &preview.Image, &preview.SiteName, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	} else if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error getting link preview: %v", err)
+	}
+	return &preview, fetchedAt, nil
+}
+
+func SaveLinkPreview(preview structs.LinkPreview) error {
+	_, err := DB.Exec(`
+		INSERT INTO link_previews (url, title, description, image, site_name, fetched_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT (url) DO UPDATE SET
+			title = excluded.title,
+			description = excluded.description,
+			image = excluded.image,
+			site_name = excluded.site_name,
+			fetched_at = excluded.fetched_at
+	`, preview.Url, preview.Title, preview.Description, preview.Image, preview.SiteName)
+	if err != nil {
+		return fmt.Errorf("error saving link preview: %v", err)
+	}
+	return nil
+}
+
+// REPORTS AND MODERATION
+
+// GetUserRole returns "user", "moderator" or "admin", or "" if the user doesn't exist
+func GetUserRole(userId int) (string, error) {
+	var role string
+	err := DB.QueryRow(`
+		SELECT role FROM users WHERE id = ?
+	`, userId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error getting user role: %v", err)
+	}
+	return role, nil
+}
+
+// GetUserSuspension returns the time the user's suspension ends, or nil if the user isn't suspended
+func GetUserSuspension(userId int) (*time.Time, error) {
+	var suspendedUntil *time.Time
+	err := DB.QueryRow(`
+		SELECT suspended_until FROM users WHERE id = ?
+	`, userId).Scan(&suspendedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting user suspension: %v", err)
+	}
+	if suspendedUntil == nil || !suspendedUntil.After(time.Now()) {
+		return nil, nil
+	}
+	return suspendedUntil, nil
+}
+
+// SuspendUser blocks the user from logging in until the given time and ends their sessions
+func SuspendUser(userId int, until time.Time) error {
+	_, err := DB.Exec(`
+		UPDATE users SET suspended_until = ? WHERE id = ?
+	`, until.UTC(), userId)
+	if err != nil {
+		return fmt.Errorf("error suspending user: %v", err)
+	}
+	return DeleteUserFromSessions(userId)
+}
+
+// GetChatMessageById returns the message, or nil if it doesn't exist or was hidden
+func GetChatMessageById(messageId int) (*structs.ChatMessage, error) {
+	return getChatMessageById(messageId, false)
+}
+
+// GetChatMessageByIdIncludingHidden is GetChatMessageById for moderation, it also finds messages that were hidden
+func GetChatMessageByIdIncludingHidden(messageId int) (*structs.ChatMessage, error) {
+	return getChatMessageById(messageId, true)
+}
+
+func getChatMessageById(messageId int, includeHidden bool) (*structs.ChatMessage, error) {
+	var message structs.ChatMessage
+	var privateChatId, groupChatId sql.NullInt64
+	err := DB.QueryRow(`
+		SELECT id, sender_id, content, created_at, private_chat_id, group_chat_id
+		FROM chat_messages
+		WHERE id = ? AND (? OR hidden = 0)
+	`, messageId, includeHidden).Scan(&message.Id, &message.SenderId, &message.Content, &message.CreatedAt, &privateChatId, &groupChatId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting chat message: %v", err)
+	}
+	message.PrivateChatId = int(privateChatId.Int64)
+	message.GroupChatId = int(groupChatId.Int64)
+	return &message, nil
+}
+
+// hideContentQueries hides reported content without deleting it, posts get their own status so every read of published posts skips them
+var hideContentQueries = map[string]string{
+	"post":          `UPDATE posts SET status = 'hidden' WHERE id = ?`,
+	"group_post":    `UPDATE group_posts SET status = 'hidden' WHERE id = ?`,
+	"comment":       `UPDATE comments SET hidden = 1 WHERE id = ?`,
+	"group_comment": `UPDATE group_comments SET hidden = 1 WHERE id = ?`,
+	"message":       `UPDATE chat_messages SET hidden = 1 WHERE id = ?`,
+}
+
+func HideContent(contentType string, contentId int) error {
+	query, ok := hideContentQueries[contentType]
+	if !ok {
+		return fmt.Errorf("content of type %s can't be hidden", contentType)
+	}
+	if _, err := DB.Exec(query, contentId); err != nil {
+		return fmt.Errorf("error hiding content: %v", err)
+	}
+	return nil
+}
+
+// HasOpenReport reports whether the user already has an unresolved report on the content
+func HasOpenReport(reporterId int, contentType string, contentId int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM reports WHERE reporter_id = ? AND content_type = ? AND content_id = ? AND status = 'open')
+	`, reporterId, contentType, contentId).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error checking report: %v", err)
+	}
+	return exists, nil
+}
+
+func InsertReport(report structs.Report) (structs.Report, error) {
+	result, err := DB.Exec(`
+		INSERT INTO reports (reporter_id, content_type, content_id, reported_user_id, reason, details, content_snapshot, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'open', CURRENT_TIMESTAMP)
+	`, report.ReporterId, report.ContentType, report.ContentId, report.ReportedUserId, report.Reason, report.Details, report.ContentSnapshot)
+	if err != nil {
+		return structs.Report{}, fmt.Errorf("error inserting report: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return structs.Report{}, err
+	}
+	report.Id = int(id)
+	report.Status = "open"
+	report.CreatedAt = time.Now().UTC()
+	return report, nil
+}
+
+const reportColumns = `id, reporter_id, content_type, content_id, reported_user_id, reason, details, content_snapshot,
+	status, assignee_id, action, resolution_note, created_at, resolved_at, resolved_by`
+
+func scanReport(row interface{ Scan(...interface{}) error }) (structs.Report, error) {
+	var report structs.Report
+	err := row.Scan(&report.Id, &report.ReporterId, &report.ContentType, &report.ContentId, &report.ReportedUserId, &report.Reason, &report.Details, &report.ContentSnapshot,
+		&report.Status, &report.AssigneeId, &report.Action, &report.ResolutionNote, &report.CreatedAt, &report.ResolvedAt, &report.ResolvedBy)
+	return report, err
+}
+
+func GetReportById(reportId int) (*structs.Report, error) {
+	report, err := scanReport(DB.QueryRow(`SELECT `+reportColumns+` FROM reports WHERE id = ?`, reportId))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting report: %v", err)
+	}
+	return &report, nil
+}
+
+// ReadReports returns one page of the moderation queue, oldest first. An empty status matches every status,
+// assigneeId -1 matches every report and 0 only the unassigned ones.
+func ReadReports(status string, assigneeId, limit, offset int) ([]structs.Report, error) {
+	conditions := []string{"1 = 1"}
+	args := make([]interface{}, 0)
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+	if assigneeId >= 0 {
+		conditions = append(conditions, "assignee_id = ?")
+		args = append(args, assigneeId)
+	}
+	args = append(args, limit, offset)
+
+	rows, err := DB.Query(`
+		SELECT `+reportColumns+`
+		FROM reports
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reports: %v", err)
+	}
+	defer rows.Close()
+
+	reports := make([]structs.Report, 0)
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning report: %v", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// AssignReport hands an open report to a moderator and reports whether it was still open
+func AssignReport(reportId, assigneeId int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE reports SET assignee_id = ? WHERE id = ? AND status = 'open'
+	`, assigneeId, reportId)
+	if err != nil {
+		return false, fmt.Errorf("error assigning report: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// ResolveReports closes the report and every other open report on the same content with the same outcome,
+// returning the ids of the users who reported it so they can be told
+func ResolveReports(resolution structs.Report) ([]int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT DISTINCT reporter_id FROM reports
+		WHERE status = 'open' AND (id = ? OR (content_type = ? AND content_id = ?))
+	`, resolution.Id, resolution.ContentType, resolution.ContentId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying reporters: %v", err)
+	}
+	reporterIds := make([]int, 0)
+	for rows.Next() {
+		var reporterId int
+		if err := rows.Scan(&reporterId); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		reporterIds = append(reporterIds, reporterId)
+	}
+	rows.Close()
+
+	_, err = tx.Exec(`
+		UPDATE reports
+		SET status = ?, action = ?, resolution_note = ?, resolved_by = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE status = 'open' AND (id = ? OR (content_type = ? AND content_id = ?))
+	`, resolution.Status, resolution.Action, resolution.ResolutionNote, resolution.ResolvedBy, resolution.Id, resolution.ContentType, resolution.ContentId)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error resolving reports: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reporterIds, nil
+}
+
+// ADMIN
+
+// SearchUsersForAdmin matches the query against names, nickname and email. An empty query or role matches everyone.
+func SearchUsersForAdmin(query, role string, limit, offset int) ([]structs.User, error) {
+	search := "%" + strings.ToLower(query) + "%"
+	rows, err := DB.Query(`
+		SELECT id, first_name, last_name, email, nickname, avatar, is_private, role, suspended_until
+		FROM users
+		WHERE (LOWER(first_name) LIKE ? OR LOWER(last_name) LIKE ? OR LOWER(nickname) LIKE ? OR LOWER(email) LIKE ?)
+		AND (? = '' OR role = ?)
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`, search, search, search, search, role, role, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error searching users: %v", err)
+	}
+	defer rows.Close()
+
+	users := make([]structs.User, 0)
+	for rows.Next() {
+		var user structs.User
+		var nickname, avatar sql.NullString
+		if err := rows.Scan(&user.Id, &user.FirstName, &user.LastName, &user.Email, &nickname, &avatar, &user.IsPrivate, &user.Role, &user.SuspendedUntil); err != nil {
+			return nil, fmt.Errorf("error scanning user: %v", err)
+		}
+		user.Nickname = nickname.String
+		user.Avatar = avatar.String
+		if user.SuspendedUntil != nil && !user.SuspendedUntil.After(time.Now()) {
+			user.SuspendedUntil = nil
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func SetUserRole(userId int, role string) error {
+	_, err := DB.Exec(`
+		UPDATE users SET role = ? WHERE id = ?
+	`, role, userId)
+	if err != nil {
+		return fmt.Errorf("error setting user role: %v", err)
+	}
+	return nil
+}
+
+func UnsuspendUser(userId int) error {
+	_, err := DB.Exec(`
+		UPDATE users SET suspended_until = NULL WHERE id = ?
+	`, userId)
+	if err != nil {
+		return fmt.Errorf("error unsuspending user: %v", err)
+	}
+	return nil
+}
+
+func DeleteGroupPost(postId int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM group_comment_reactions WHERE comment_id IN (SELECT id FROM group_comments WHERE post_id = ?)`,
+		`DELETE FROM group_comments WHERE post_id = ?`,
+		`DELETE FROM group_post_tags WHERE group_post_id = ?`,
+		`DELETE FROM mentions WHERE post_id = ? AND content_type IN ('group_post', 'group_comment')`,
+		`DELETE FROM bookmarks WHERE group_post_id = ?`,
+		`DELETE FROM group_posts WHERE id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, postId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error deleting group post: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteCommentThread deletes the comment together with all the replies under it
+func DeleteCommentThread(commentId int) error {
+	return deleteCommentThread("comments", "comment_reactions", "comment", commentId)
+}
+
+func DeleteGroupCommentThread(commentId int) error {
+	return deleteCommentThread("group_comments", "group_comment_reactions", "group_comment", commentId)
+}
+
+func deleteCommentThread(table, reactionsTable, mentionType string, commentId int) error {
+	ids, err := queryIds(`
+		WITH RECURSIVE thread (id) AS (
+			SELECT id FROM `+table+` WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM `+table+` c
+			JOIN thread t ON c.parent_id = t.id
+		)
+		SELECT id FROM thread
+	`, commentId)
+	if err != nil {
+		return fmt.Errorf("error reading comment thread: %v", err)
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		queries := []string{
+			`DELETE FROM ` + reactionsTable + ` WHERE comment_id = ?`,
+			`DELETE FROM mentions WHERE content_type = '` + mentionType + `' AND content_id = ?`,
+			`DELETE FROM ` + table + ` WHERE id = ?`,
+		}
+		for _, query := range queries {
+			if _, err := tx.Exec(query, id); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("error deleting comment: %v", err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func DeleteChatMessage(messageId int) error {
+	_, err := DB.Exec(`
+		DELETE FROM chat_messages WHERE id = ?
+	`, messageId)
+	if err != nil {
+		return fmt.Errorf("error deleting chat message: %v", err)
+	}
+	return nil
+}
+
+func GetPlatformStats() (structs.PlatformStats, error) {
+	var stats structs.PlatformStats
+	err := DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE role = 'moderator'),
+			(SELECT COUNT(*) FROM users WHERE role = 'admin'),
+			(SELECT COUNT(*) FROM users WHERE suspended_until > ?),
+			(SELECT COUNT(*) FROM sessions WHERE expiration > ?),
+			(SELECT COUNT(*) FROM posts WHERE status = 'published'),
+			(SELECT COUNT(*) FROM comments WHERE hidden = 0),
+			(SELECT COUNT(*) FROM groups),
+			(SELECT COUNT(*) FROM group_posts WHERE status = 'published'),
+			(SELECT COUNT(*) FROM group_comments WHERE hidden = 0),
+			(SELECT COUNT(*) FROM chat_messages WHERE hidden = 0),
+			(SELECT COUNT(*) FROM reports WHERE status = 'open')
+	`, time.Now().UTC(), time.Now()).Scan(&stats.Users, &stats.Moderators, &stats.Admins, &stats.SuspendedUsers, &stats.ActiveSessions,
+		&stats.Posts, &stats.Comments, &stats.Groups, &stats.GroupPosts, &stats.GroupComments, &stats.ChatMessages, &stats.OpenReports)
+	if err != nil {
+		return structs.PlatformStats{}, fmt.Errorf("error reading platform stats: %v", err)
+	}
+	return stats, nil
+}
+
+// InsertAuditLogEntry records an admin or moderator action, the table refuses updates and deletes
+func InsertAuditLogEntry(entry structs.AuditLogEntry) error {
+	_, err := DB.Exec(`
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, details, created_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, entry.ActorId, entry.Action, entry.TargetType, entry.TargetId, entry.Details)
+	if err != nil {
+		return fmt.Errorf("error inserting audit log entry: %v", err)
+	}
+	return nil
+}
+
+// ReadAuditLog returns one page of the audit log, newest first, actorId 0 returns everyone's actions
+func ReadAuditLog(actorId, limit, offset int) ([]structs.AuditLogEntry, error) {
+	rows, err := DB.Query(`
+		SELECT id, actor_id, action, target_type, target_id, details, created_at
+		FROM audit_log
+		WHERE ? = 0 OR actor_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, actorId, actorId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying audit log: %v", err)
+	}
+	defer rows.Close()
+
+	entries := make([]structs.AuditLogEntry, 0)
+	for rows.Next() {
+		var entry structs.AuditLogEntry
+		if err := rows.Scan(&entry.Id, &entry.ActorId, &entry.Action, &entry.TargetType, &entry.TargetId, &entry.Details, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning audit log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PINNED POSTS
+
+// PinPost pins the post to the top of its author's profile unless they already have maxPinned published posts pinned,
+// and reports whether it was pinned
+func PinPost(postId, userId, maxPinned int) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE posts SET pinned_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND pinned_at IS NULL
+		AND (SELECT COUNT(*) FROM posts WHERE user_id = ? AND status = 'published' AND pinned_at IS NOT NULL) < ?
+	`, postId, userId, maxPinned)
+	if err != nil {
+		return false, fmt.Errorf("error pinning post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func UnpinPost(postId int) error {
+	_, err := DB.Exec(`
+		UPDATE posts SET pinned_at = NULL WHERE id = ?
+	`, postId)
+	if err != nil {
+		return fmt.Errorf("error unpinning post: %v", err)
+	}
+	return nil
+}
+
+func PinGroupPost(postId int) error {
+	_, err := DB.Exec(`
+		UPDATE group_posts SET pinned_at = CURRENT_TIMESTAMP WHERE id = ? AND pinned_at IS NULL
+	`, postId)
+	if err != nil {
+		return fmt.Errorf("error pinning group post: %v", err)
+	}
+	return nil
+}
+
+func UnpinGroupPost(postId int) error {
+	_, err := DB.Exec(`
+		UPDATE group_posts SET pinned_at = NULL WHERE id = ?
+	`, postId)
+	if err != nil {
+		return fmt.Errorf("error unpinning group post: %v", err)
+	}
+	return nil
+}
+
+// STORIES
+
+// storyColumns are read by scanStory. Stories are aliased as p so postVisibilityCondition applies to them as well.
+const storyColumns = `p.id, p.user_id, ` + authorNameColumn + `, COALESCE(u.avatar, ''), p.content, p.media, p.privacy, p.created_at, p.expires_at`
+
+func scanStory(row interface{ Scan(...interface{}) error }, extra ...interface{}) (structs.Story, error) {
+	var story structs.Story
+	dest := []interface{}{&story.Id, &story.UserId, &story.CreatorName, &story.ProfilePicture, &story.Content, &story.Media, &story.Privacy, &story.CreatedAt, &story.ExpiresAt}
+	err := row.Scan(append(dest, extra...)...)
+	return story, err
+}
+
+func InsertStory(story structs.Story) (int, error) {
+	result, err := DB.Exec(`
+		INSERT INTO stories (user_id, content, media, privacy, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, story.UserId, story.Content, story.Media, story.Privacy, story.CreatedAt.UTC(), story.ExpiresAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("error inserting story: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// GetStoryById returns the story, or nil if it doesn't exist or has expired. Privacy is returned as stored.
+func GetStoryById(storyId int) (*structs.Story, error) {
+	story, err := scanStory(DB.QueryRow(`
+		SELECT `+storyColumns+`
+		FROM stories p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.id = ? AND p.expires_at > ?
+	`, storyId, time.Now().UTC()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting story: %v", err)
+	}
+	return &story, nil
+}
+
+// IsStoryVisibleToUser applies the audience rules of posts to a story that hasn't expired yet
+func IsStoryVisibleToUser(storyId, userId int) (bool, error) {
+	var exists int
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM stories p WHERE p.id = ? AND p.expires_at > ? AND `+postVisibilityCondition+`)
+	`, storyId, time.Now().UTC(), userId, userId, userId).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return exists == 1, nil
+}
+
+// ReadStories returns the active stories the viewer is allowed to see, of one author or of everyone when authorId is 0.
+// The viewer's own stories come first, then each author's stories in the order they were posted.
+func ReadStories(viewerId, authorId int) ([]structs.Story, error) {
+	stories := make([]structs.Story, 0)
+	rows, err := DB.Query(`
+		SELECT `+storyColumns+`,
+			p.user_id = ? OR EXISTS(SELECT 1 FROM story_views sv WHERE sv.story_id = p.id AND sv.viewer_id = ?),
+			CASE WHEN p.user_id = ? THEN (SELECT COUNT(*) FROM story_views sv WHERE sv.story_id = p.id) ELSE 0 END
+		FROM stories p
+		LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.expires_at > ? AND (? = 0 OR p.user_id = ?) AND `+postVisibilityCondition+`
+		ORDER BY p.user_id = ? DESC, p.user_id, p.created_at
+	`, viewerId, viewerId, viewerId, time.Now().UTC(), authorId, authorId, viewerId, viewerId, viewerId, viewerId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stories: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seen bool
+		var viewCount int
+		story, err := scanStory(rows, &seen, &viewCount)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning story: %v", err)
+		}
+		story.Seen = seen
+		story.ViewCount = viewCount
+		if story.UserId != viewerId && story.Privacy != "public" && story.Privacy != "private" {
+			story.Privacy = "private"
+		}
+		stories = append(stories, story)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stories, nil
+}
+
+// MarkStorySeen records that the user viewed the story, viewing it again keeps the first view
+func MarkStorySeen(storyId, viewerId int) error {
+	_, err := DB.Exec(`
+		INSERT OR IGNORE INTO story_views (story_id, viewer_id) VALUES (?, ?)
+	`, storyId, viewerId)
+	if err != nil {
+		return fmt.Errorf("error marking story as seen: %v", err)
+	}
+	return nil
+}
+
+// ReadStoryViewers returns one page of the users who viewed the story, the latest viewer first
+func ReadStoryViewers(storyId, limit, offset int) ([]structs.StoryViewer, error) {
+	viewers := make([]structs.StoryViewer, 0)
+	rows, err := DB.Query(`
+		SELECT sv.viewer_id, `+authorNameColumn+`, COALESCE(u.avatar, ''), sv.viewed_at
+		FROM story_views sv
+		JOIN users u ON u.id = sv.viewer_id
+		WHERE sv.story_id = ?
+		ORDER BY sv.viewed_at DESC, sv.viewer_id
+		LIMIT ? OFFSET ?
+	`, storyId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying story viewers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var viewer structs.StoryViewer
+		if err := rows.Scan(&viewer.UserId, &viewer.Name, &viewer.ProfilePicture, &viewer.ViewedAt); err != nil {
+			return nil, fmt.Errorf("error scanning story viewer: %v", err)
+		}
+		viewers = append(viewers, viewer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return viewers, nil
+}
+
+// DeleteStory removes the story with its views, deleting its media file is left to the caller
+func DeleteStory(storyId int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM story_views WHERE story_id = ?`, storyId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error deleting story views: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM stories WHERE id = ?`, storyId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error deleting story: %v", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteExpiredStories removes the stories that expired before now with their views
+// and returns the media file names of the deleted stories so they can be removed from storage
+func DeleteExpiredStories(now time.Time) ([]string, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT media FROM stories WHERE expires_at <= ? AND media != ''
+	`, now.UTC())
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying expired stories: %v", err)
+	}
+	media := make([]string, 0)
+	for rows.Next() {
+		var fileName string
+		if err := rows.Scan(&fileName); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning expired story: %v", err)
+		}
+		media = append(media, fileName)
+	}
+	rows.Close()
+
+	queries := []string{
+		`DELETE FROM story_views WHERE story_id IN (SELECT id FROM stories WHERE expires_at <= ?)`,
+		`DELETE FROM stories WHERE expires_at <= ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, now.UTC()); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error deleting expired stories: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// GROUP ROLES
+
+// GetGroupMemberRole returns the user's role in the group, or "" if they aren't a member
+func GetGroupMemberRole(userId, groupId int) (string, error) {
+	var role string
+	err := DB.QueryRow(`
+		SELECT role FROM group_members WHERE group_id = ? AND requester_id = ?
+	`, groupId, userId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error getting group member role: %v", err)
+	}
+	return role, nil
+}
+
+func SetGroupMemberRole(userId, groupId int, role string) error {
+	_, err := DB.Exec(`
+		UPDATE group_members SET role = ? WHERE group_id = ? AND requester_id = ?
+	`, role, groupId, userId)
+	if err != nil {
+		return fmt.Errorf("error setting group member role: %v", err)
+	}
+	return nil
+}
+
+// GetGroupMemberIdsByRole returns the members of the group that have one of the roles
+func GetGroupMemberIdsByRole(groupId int, roles ...string) ([]int, error) {
+	if len(roles) == 0 {
+		return []int{}, nil
+	}
+	args := []interface{}{groupId}
+	for _, role := range roles {
+		args = append(args, role)
+	}
+	return queryIds(`
+		SELECT requester_id FROM group_members
+		WHERE group_id = ? AND role IN (?`+strings.Repeat(", ?", len(roles)-1)+`)
+	`, args...)
+}
+
+// ReadGroupMembers returns the members of the group with their roles, the owner first and then by role and name
+func ReadGroupMembers(groupId int) ([]structs.GroupMember, error) {
+	members := make([]structs.GroupMember, 0)
+	rows, err := DB.Query(`
+		SELECT gm.requester_id, `+authorNameColumn+`, COALESCE(u.avatar, ''), gm.role
+		FROM group_members gm
+		JOIN users u ON u.id = gm.requester_id
+		WHERE gm.group_id = ?
+		ORDER BY CASE gm.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 WHEN 'moderator' THEN 2 ELSE 3 END, 2
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group members: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member structs.GroupMember
+		if err := rows.Scan(&member.UserId, &member.Name, &member.ProfilePicture, &member.Role); err != nil {
+			return nil, fmt.Errorf("error scanning group member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GROUP OWNERSHIP AND ARCHIVING
+
+func IsGroupArchived(groupId int) (bool, error) {
+	var archived bool
+	err := DB.QueryRow(`
+		SELECT archived_at IS NOT NULL FROM groups WHERE id = ?
+	`, groupId).Scan(&archived)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error checking if group is archived: %v", err)
+	}
+	return archived, nil
+}
+
+func SetGroupArchived(groupId int, archived bool) error {
+	query := `UPDATE groups SET archived_at = NULL WHERE id = ?`
+	if archived {
+		query = `UPDATE groups SET archived_at = CURRENT_TIMESTAMP WHERE id = ? AND archived_at IS NULL`
+	}
+	if _, err := DB.Exec(query, groupId); err != nil {
+		return fmt.Errorf("error archiving group: %v", err)
+	}
+	return nil
+}
+
+// SetPendingGroupOwner offers the group to a member, 0 withdraws the offer
+func SetPendingGroupOwner(groupId, userId int) error {
+	_, err := DB.Exec(`
+		UPDATE groups SET pending_owner_id = ? WHERE id = ?
+	`, userId, groupId)
+	if err != nil {
+		return fmt.Errorf("error setting pending group owner: %v", err)
+	}
+	return nil
+}
+
+// SetGroupNotificationStatus answers the user's pending notification of the given type in the group
+func SetGroupNotificationStatus(receiverId, groupId int, notificationType, status string) error {
+	_, err := DB.Exec(`
+		UPDATE group_notifications SET status = ?
+		WHERE receiver_id = ? AND group_id = ? AND type = ? AND status = 'pending'
+	`, status, receiverId, groupId, notificationType)
+	if err != nil {
+		return fmt.Errorf("error updating notification status: %v", err)
+	}
+	return nil
+}
+
+// TransferGroupOwnership makes the pending owner the owner of the group and the previous owner an admin.
+// It reports whether the transfer was still waiting for the new owner, so an offer withdrawn in the meantime isn't accepted.
+func TransferGroupOwnership(groupId, fromUserId, toUserId int) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE groups SET creator_id = ?, pending_owner_id = 0
+		WHERE id = ? AND creator_id = ? AND pending_owner_id = ?
+	`, toUserId, groupId, fromUserId, toUserId)
+	if err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("error transferring group: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	roles := map[int]string{fromUserId: "admin", toUserId: "owner"}
+	for userId, role := range roles {
+		if _, err := tx.Exec(`
+			UPDATE group_members SET role = ? WHERE group_id = ? AND requester_id = ?
+		`, role, groupId, userId); err != nil {
+			tx.Rollback()
+			return false, fmt.Errorf("error updating group roles: %v", err)
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// DeleteGroup removes the group with everything posted, planned or sent in it
+func DeleteGroup(groupId int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM group_comment_reactions WHERE comment_id IN (SELECT id FROM group_comments WHERE group_id = ?)`,
+		`DELETE FROM group_comments WHERE group_id = ?`,
+		`DELETE FROM group_post_tags WHERE group_id = ?`,
+		`DELETE FROM bookmarks WHERE group_post_id IN (SELECT id FROM group_posts WHERE group_id = ?)`,
+		`DELETE FROM group_posts WHERE group_id = ?`,
+		`DELETE FROM group_event_choice WHERE event_id IN (SELECT id FROM group_events WHERE group_id = ?)`,
+		`DELETE FROM group_events WHERE group_id = ?`,
+		`DELETE FROM join_requests WHERE group_id = ?`,
+		`DELETE FROM group_notifications WHERE group_id = ?`,
+		`DELETE FROM mentions WHERE group_id = ?`,
+		`DELETE FROM group_bans WHERE group_id = ?`,
+		`DELETE FROM group_invite_link_uses WHERE link_id IN (SELECT id FROM group_invite_links WHERE group_id = ?)`,
+		`DELETE FROM group_invite_links WHERE group_id = ?`,
+		`DELETE FROM group_questions WHERE group_id = ?`,
+		`DELETE FROM group_audit_log WHERE group_id = ?`,
+		`DELETE FROM chat_messages WHERE group_chat_id = ?`,
+		`DELETE FROM group_members WHERE group_id = ?`,
+		`DELETE FROM groups WHERE id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, groupId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error deleting group: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GROUP VISIBILITY
+
+// groupListedCondition limits groups aliased as g to the ones the viewer can find, secret groups are only listed
+// for their members. It takes the viewer id once.
+const groupListedCondition = `(
+	g.visibility != 'secret' OR
+	EXISTS(SELECT 1 FROM group_members WHERE group_id = g.id AND requester_id = ?)
+)`
+
+// groupReadableCondition limits groups aliased as g to the ones whose content the viewer can read: public groups
+// and the groups they are a member of. It takes the viewer id once.
+const groupReadableCondition = `(
+	g.visibility = 'public' OR
+	EXISTS(SELECT 1 FROM group_members WHERE group_id = g.id AND requester_id = ?)
+)`
+
+// CanUserReadGroup reports whether the user can read the posts, comments, events and members of the group
+func CanUserReadGroup(userId, groupId int) (bool, error) {
+	var readable bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM groups g WHERE g.id = ? AND `+groupReadableCondition+`)
+	`, groupId, userId).Scan(&readable)
+	return readable, err
+}
+
+// CanUserSeeGroup reports whether the group's details can be shown to the user. Secret groups are hidden from
+// everyone but their members and the users they invited.
+func CanUserSeeGroup(userId, groupId int) (bool, error) {
+	var visible bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM groups g
+			WHERE g.id = ? AND (`+groupListedCondition+` OR `+pendingInvitationCondition+`)
+		)
+	`, groupId, userId, userId).Scan(&visible)
+	return visible, err
+}
+
+// pendingInvitationCondition matches groups aliased as g the user was invited to and hasn't answered yet.
+// It takes the user id once.
+const pendingInvitationCondition = `EXISTS(
+	SELECT 1 FROM group_notifications
+	WHERE group_id = g.id AND receiver_id = ? AND type = 'invite_group_request' AND status = 'pending'
+)`
+
+// HasPendingGroupInvitation reports whether the user was invited to the group and hasn't answered yet
+func HasPendingGroupInvitation(userId, groupId int) (bool, error) {
+	var invited bool
+	err := DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM groups g WHERE g.id = ? AND `+pendingInvitationCondition+`)
+	`, groupId, userId).Scan(&invited)
+	return invited, err
+}
+
+// GROUP DETAILS
+
+// UpdateGroupDetails saves the name, description, cover, rules, visibility and post approval setting of the group
+func UpdateGroupDetails(group structs.Group) error {
+	_, err := DB.Exec(`
+		UPDATE groups SET name = ?, description = ?, cover = ?, rules = ?, visibility = ?, post_approval = ?
+		WHERE id = ?
+	`, group.Name, group.Description, group.Cover, group.Rules, group.Visibility, group.PostApproval, group.Id)
+	if err != nil {
+		return fmt.Errorf("error updating group details: %v", err)
+	}
+	return nil
+}
+
+// GROUP BANS
+
+// BanGroupMember removes the user from the group and keeps them out until the ban expires, a nil expiry never does.
+// Their pending join request or invitation is declined with it.
+func BanGroupMember(groupId, userId, bannedBy int, reason string, expiresAt *time.Time) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM group_members WHERE group_id = ? AND requester_id = ?`,
+		`DELETE FROM join_requests WHERE group_id = ? AND requester_id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, groupId, userId); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error removing banned member: %v", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE group_notifications SET status = 'declined'
+		WHERE group_id = ? AND status = 'pending' AND (
+			(type = 'join_request' AND sender_id = ?) OR (type = 'invite_group_request' AND receiver_id = ?)
+		)
+	`, groupId, userId, userId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error declining pending requests: %v", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO group_bans (group_id, user_id, banned_by, reason, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, groupId, userId, bannedBy, reason, time.Now().UTC(), expiresAt); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error banning group member: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// UnbanGroupMember lifts the ban, it reports whether the user was banned at all
+func UnbanGroupMember(groupId, userId int) (bool, error) {
+	result, err := DB.Exec(`
+		DELETE FROM group_bans WHERE group_id = ? AND user_id = ?
+	`, groupId, userId)
+	if err != nil {
+		return false, fmt.Errorf("error unbanning group member: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetActiveGroupBan returns the user's ban from the group, nil if they aren't banned or the ban expired
+func GetActiveGroupBan(groupId, userId int) (*structs.GroupBan, error) {
+	var ban structs.GroupBan
+	err := DB.QueryRow(`
+		SELECT user_id, banned_by, reason, created_at, expires_at
+		FROM group_bans
+		WHERE group_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)
+	`, groupId, userId, time.Now().UTC()).Scan(&ban.UserId, &ban.BannedBy, &ban.Reason, &ban.CreatedAt, &ban.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// ReadGroupBans lists the bans of the group that haven't expired, newest first
+func ReadGroupBans(groupId, limit, offset int) ([]structs.GroupBan, error) {
+	bans := make([]structs.GroupBan, 0)
+	rows, err := DB.Query(`
+		SELECT gb.user_id, `+authorNameColumn+`, COALESCE(u.avatar, ''), gb.banned_by, gb.reason, gb.created_at, gb.expires_at
+		FROM group_bans gb
+		JOIN users u ON u.id = gb.user_id
+		WHERE gb.group_id = ? AND (gb.expires_at IS NULL OR gb.expires_at > ?)
+		ORDER BY gb.created_at DESC
+		LIMIT ? OFFSET ?
+	`, groupId, time.Now().UTC(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group bans: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ban structs.GroupBan
+		if err := rows.Scan(&ban.UserId, &ban.Name, &ban.ProfilePicture, &ban.BannedBy, &ban.Reason, &ban.CreatedAt, &ban.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("error scanning group ban: %v", err)
+		}
+		bans = append(bans, ban)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
+
+// GROUP INVITE LINKS
+
+// inviteLinkColumns are read by scanInviteLink
+const inviteLinkColumns = `id, group_id, token, created_by, auto_approve, max_uses, uses, created_at, expires_at, revoked_at IS NOT NULL`
+
+func scanInviteLink(row interface{ Scan(...interface{}) error }) (structs.GroupInviteLink, error) {
+	var link structs.GroupInviteLink
+	err := row.Scan(&link.Id, &link.GroupId, &link.Token, &link.CreatedBy, &link.AutoApprove, &link.MaxUses, &link.Uses, &link.CreatedAt, &link.ExpiresAt, &link.Revoked)
+	return link, err
+}
+
+func InsertGroupInviteLink(link structs.GroupInviteLink) (int, error) {
+	result, err := DB.Exec(`
+		INSERT INTO group_invite_links (group_id, token, created_by, auto_approve, max_uses, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, link.GroupId, link.Token, link.CreatedBy, link.AutoApprove, link.MaxUses, time.Now().UTC(), link.ExpiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting invite link: %v", err)
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func GetGroupInviteLinkById(linkId int) (*structs.GroupInviteLink, error) {
+	link, err := scanInviteLink(DB.QueryRow(`
+		SELECT `+inviteLinkColumns+` FROM group_invite_links WHERE id = ?
+	`, linkId))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting invite link: %v", err)
+	}
+	return &link, nil
+}
+
+func GetGroupInviteLinkByToken(token string) (*structs.GroupInviteLink, error) {
+	link, err := scanInviteLink(DB.QueryRow(`
+		SELECT `+inviteLinkColumns+` FROM group_invite_links WHERE token = ?
+	`, token))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting invite link: %v", err)
+	}
+	return &link, nil
+}
+
+// ReadGroupInviteLinks lists every link of the group, revoked and expired ones included, newest first
+func ReadGroupInviteLinks(groupId int) ([]structs.GroupInviteLink, error) {
+	links := make([]structs.GroupInviteLink, 0)
+	rows, err := DB.Query(`
+		SELECT `+inviteLinkColumns+` FROM group_invite_links
+		WHERE group_id = ?
+		ORDER BY id DESC
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying invite links: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanInviteLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning invite link: %v", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func RevokeGroupInviteLink(linkId int) error {
+	_, err := DB.Exec(`
+		UPDATE group_invite_links SET revoked_at = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, time.Now().UTC(), linkId)
+	if err != nil {
+		return fmt.Errorf("error revoking invite link: %v", err)
+	}
+	return nil
+}
+
+// JoinGroupWithInviteLink counts a use of the link and adds the user to the group in one transaction. It reports
+// false, and changes nothing, when the link was revoked, expired or ran out of uses in the meantime.
+func JoinGroupWithInviteLink(linkId, userId, groupId int) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	used, err := useGroupInviteLink(tx, linkId, userId, "joined")
+	if err != nil || !used {
+		tx.Rollback()
+		return false, err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO group_members (group_id, requester_id) VALUES (?, ?)
+	`, groupId, userId); err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("error adding group member: %v", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// RequestToJoinWithInviteLink counts a use of the link and sends the user's join request in one transaction,
+// reporting false like JoinGroupWithInviteLink
+func RequestToJoinWithInviteLink(linkId, userId, groupId int, answers []structs.JoinRequestAnswer) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	used, err := useGroupInviteLink(tx, linkId, userId, "requested")
+	if err != nil || !used {
+		tx.Rollback()
+		return false, err
+	}
+	if err := insertJoinRequest(tx, userId, groupId, "request", answers); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// useGroupInviteLink counts a use of the link and records who used it
+func useGroupInviteLink(tx *sql.Tx, linkId, userId int, status string) (bool, error) {
+	now := time.Now().UTC()
+	result, err := tx.Exec(`
+		UPDATE group_invite_links SET uses = uses + 1
+		WHERE id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_uses = 0 OR uses < max_uses)
+	`, linkId, now)
+	if err != nil {
+		return false, fmt.Errorf("error using invite link: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO group_invite_link_uses (link_id, user_id, status, used_at)
+		VALUES (?, ?, ?, ?)
+	`, linkId, userId, status, now); err != nil {
+		return false, fmt.Errorf("error recording invite link use: %v", err)
+	}
+	return true, nil
+}
+
+// SetInviteLinkUseStatus records the answer to a join request made through one of the group's invite links
+func SetInviteLinkUseStatus(groupId, userId int, status string) error {
+	_, err := DB.Exec(`
+		UPDATE group_invite_link_uses SET status = ?
+		WHERE user_id = ? AND status = 'requested' AND link_id IN (SELECT id FROM group_invite_links WHERE group_id = ?)
+	`, status, userId, groupId)
+	if err != nil {
+		return fmt.Errorf("error updating invite link use: %v", err)
+	}
+	return nil
+}
+
+func ReadGroupInviteLinkUses(linkId, limit, offset int) ([]structs.GroupInviteLinkUse, error) {
+	uses := make([]structs.GroupInviteLinkUse, 0)
+	rows, err := DB.Query(`
+		SELECT lu.user_id, `+authorNameColumn+`, COALESCE(u.avatar, ''), lu.status, lu.used_at
+		FROM group_invite_link_uses lu
+		JOIN users u ON u.id = lu.user_id
+		WHERE lu.link_id = ?
+		ORDER BY lu.used_at DESC
+		LIMIT ? OFFSET ?
+	`, linkId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying invite link uses: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var use structs.GroupInviteLinkUse
+		if err := rows.Scan(&use.UserId, &use.Name, &use.ProfilePicture, &use.Status, &use.UsedAt); err != nil {
+			return nil, fmt.Errorf("error scanning invite link use: %v", err)
+		}
+		uses = append(uses, use)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return uses, nil
+}
+
+// JOIN REQUEST LIFECYCLE
+
+// WithdrawJoinRequest withdraws the user's pending request to join the group, it reports whether there was one
+func WithdrawJoinRequest(requesterID, groupID int) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE join_requests SET status = 'withdrawn', responded_at = ?
+		WHERE requester_id = ? AND group_id = ? AND kind = 'request' AND status = 'pending'
+	`, time.Now().UTC(), requesterID, groupID)
+	if err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("error withdrawing join request: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE group_notifications SET status = 'withdrawn'
+		WHERE sender_id = ? AND group_id = ? AND type = 'join_request' AND status = 'pending'
+	`, requesterID, groupID); err != nil {
+		tx.Rollback()
+		return false, fmt.Errorf("error updating join request notifications: %v", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// ExpireJoinRequests expires the requests and invitations still pending since before the given time,
+// together with their notifications, and returns them
+func ExpireJoinRequests(createdBefore time.Time) ([]structs.GroupJoinRequest, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT requester_id, group_id, kind, status, created_at
+		FROM join_requests
+		WHERE status = 'pending' AND created_at < ?
+	`, createdBefore.UTC())
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("error querying stale join requests: %v", err)
+	}
+	expired := make([]structs.GroupJoinRequest, 0)
+	for rows.Next() {
+		var request structs.GroupJoinRequest
+		if err := rows.Scan(&request.UserId, &request.GroupId, &request.Kind, &request.Status, &request.CreatedAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, fmt.Errorf("error scanning stale join request: %v", err)
+		}
+		expired = append(expired, request)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	for i, request := range expired {
+		if _, err := tx.Exec(`
+			UPDATE join_requests SET status = 'expired', responded_at = ?
+			WHERE requester_id = ? AND group_id = ? AND status = 'pending'
+		`, now, request.UserId, request.GroupId); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error expiring join request: %v", err)
+		}
+
+		// the approvers were notified of a request, the invited user of an invitation
+		notificationQuery := `
+			UPDATE group_notifications SET status = 'expired'
+			WHERE sender_id = ? AND group_id = ? AND type = 'join_request' AND status = 'pending'
+		`
+		if request.Kind == "invitation" {
+			notificationQuery = `
+				UPDATE group_notifications SET status = 'expired'
+				WHERE receiver_id = ? AND group_id = ? AND type = 'invite_group_request' AND status = 'pending'
+			`
+		}
+		if _, err := tx.Exec(notificationQuery, request.UserId, request.GroupId); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("error expiring join request notifications: %v", err)
+		}
+
+		expired[i].Status = "expired"
+		expired[i].RespondedAt = &now
+	}
+
+	return expired, tx.Commit()
+}
+
+// MEMBERSHIP QUESTIONS
+
+// ReadGroupQuestions returns the membership questions of the group in the order they are asked
+func ReadGroupQuestions(groupId int) ([]structs.GroupQuestion, error) {
+	questions := make([]structs.GroupQuestion, 0)
+	rows, err := DB.Query(`
+		SELECT id, question FROM group_questions
+		WHERE group_id = ?
+		ORDER BY position
+	`, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group questions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var question structs.GroupQuestion
+		if err := rows.Scan(&question.Id, &question.Question); err != nil {
+			return nil, fmt.Errorf("error scanning group question: %v", err)
+		}
+		questions = append(questions, question)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// ReplaceGroupQuestions replaces the membership questions of the group, an empty list removes them
+func ReplaceGroupQuestions(groupId int, questions []string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM group_questions WHERE group_id = ?`, groupId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("error deleting group questions: %v", err)
+	}
+	for position, question := range questions {
+		if _, err := tx.Exec(`
+			INSERT INTO group_questions (group_id, position, question)
+			VALUES (?, ?, ?)
+		`, groupId, position, question); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error inserting group question: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReadPendingJoinRequests lists the requests to join the group waiting for an answer, oldest first, with the
+// applicants' answers
+func ReadPendingJoinRequests(groupId, limit, offset int) ([]structs.PendingJoinRequest, error) {
+	requests := make([]structs.PendingJoinRequest, 0)
+	rows, err := DB.Query(`
+		SELECT jr.requester_id, `+authorNameColumn+`, COALESCE(u.avatar, ''), jr.answers, jr.created_at
+		FROM join_requests jr
+		JOIN users u ON u.id = jr.requester_id
+		WHERE jr.group_id = ? AND jr.kind = 'request' AND jr.status = 'pending'
+		ORDER BY jr.created_at
+		LIMIT ? OFFSET ?
+	`, groupId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending join requests: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var request structs.PendingJoinRequest
+		var answers string
+		if err := rows.Scan(&request.UserId, &request.Name, &request.ProfilePicture, &answers, &request.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning pending join request: %v", err)
+		}
+		if err := json.Unmarshal([]byte(answers), &request.Answers); err != nil {
+			return nil, fmt.Errorf("error decoding join request answers: %v", err)
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GROUP POST APPROVAL
+
+// ReadPendingGroupPosts lists the posts of the group waiting for approval, oldest first
+func ReadPendingGroupPosts(groupId, limit, offset int) ([]structs.GroupPost, error) {
+	posts := make([]structs.GroupPost, 0)
+	rows, err := DB.Query(`
+		SELECT gp.id, gp.group_id, gp.user_id, COALESCE(u.avatar, ''), gp.title, gp.content, gp.photo, gp.status
+		FROM group_posts gp
+		LEFT JOIN users u ON u.id = gp.user_id
+		WHERE gp.group_id = ? AND gp.status = 'pending'
+		ORDER BY gp.id
+		LIMIT ? OFFSET ?
+	`, groupId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending group posts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post structs.GroupPost
+		if err := rows.Scan(&post.Id, &post.GroupId, &post.UserId, &post.ProfilePicture, &post.Title, &post.Content, &post.Photo, &post.Status); err != nil {
+			return nil, fmt.Errorf("error scanning pending group post: %v", err)
+		}
+		post.Comments = []structs.GroupComment{}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// ReviewGroupPost moves the pending post to "published" or "rejected" and reports whether it was still pending,
+// so two moderators can't both answer it
+func ReviewGroupPost(postId int, status string) (bool, error) {
+	result, err := DB.Exec(`
+		UPDATE group_posts SET status = ?
+		WHERE id = ? AND status = 'pending'
+	`, status, postId)
+	if err != nil {
+		return false, fmt.Errorf("error reviewing group post: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// GROUP AUDIT LOG
+
+// InsertGroupAuditLogEntry records a membership or moderation change in the group
+func InsertGroupAuditLogEntry(entry structs.GroupAuditLogEntry) error {
+	_, err := DB.Exec(`
+		INSERT INTO group_audit_log (group_id, actor_id, action, target_type, target_id, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, entry.GroupId, entry.ActorId, entry.Action, entry.TargetType, entry.TargetId, entry.Details)
+	if err != nil {
+		return fmt.Errorf("error inserting group audit log entry: %v", err)
+	}
+	return nil
+}
+
+// ReadGroupAuditLog returns one page of the group's audit log, newest first
+func ReadGroupAuditLog(groupId int, filter structs.GroupAuditLogFilter, limit, offset int) ([]structs.GroupAuditLogEntry, error) {
+	conditions := []string{"group_id = ?"}
+	args := []interface{}{groupId}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.ActorId > 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorId)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetId > 0 {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, filter.TargetId)
+	}
+	args = append(args, limit, offset)
+
+	rows, err := DB.Query(`
+		SELECT id, group_id, actor_id, action, target_type, target_id, details, created_at
+		FROM group_audit_log
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group audit log: %v", err)
+	}
+	defer rows.Close()
+
+	entries := make([]structs.GroupAuditLogEntry, 0)
+	for rows.Next() {
+		var entry structs.GroupAuditLogEntry
+		if err := rows.Scan(&entry.Id, &entry.GroupId, &entry.ActorId, &entry.Action, &entry.TargetType, &entry.TargetId, &entry.Details, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning group audit log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GROUP EVENT EDITS
+
+// GetGroupEventById returns the event without the viewer's choice, or nil if it doesn't exist
+func GetGroupEventById(eventId int) (*structs.Event, error) {
+	var event structs.Event
+	var options string
+	err := DB.QueryRow(`
+		SELECT id, group_id, creator_id, name, description, time, options
+		FROM group_events
+		WHERE id = ?
+	`, eventId).Scan(&event.Id, &event.GroupId, &event.CreatorId, &event.Name, &event.Description, &event.Time, &options)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting event: %v", err)
+	}
+	event.Options = strings.Split(options, ",")
+	return &event, nil
+}
+
+// UpdateGroupEvent saves the name, description and time of the event. The options can't be changed, members
+// have already chosen between them.
+func UpdateGroupEvent(event structs.Event) error {
+	_, err := DB.Exec(`
+		UPDATE group_events SET name = ?, description = ?, time = ? WHERE id = ?
+	`, event.Name, event.Description, event.Time, event.Id)
+	if err != nil {
+		return fmt.Errorf("error updating event: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"path/filepath"
+	"social-network/structs"
+	"testing"
+)
+
+// openTestDB runs the migrations on a fresh database file that is removed with the test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := OpenDB(filepath.Join(t.TempDir(), "test.db"), "file://migrations"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { DB.Close() })
+}
+
+func createTestUser(t *testing.T, email string) int {
+	t.Helper()
+	if err := InsertUser("Test", "User", email, "2000-01-01", nil, nil, nil, false, []byte("hash")); err != nil {
+		t.Fatal(err)
+	}
+	user, err := GetUserByEmail(email)
+	if err != nil || user == nil {
+		t.Fatalf("reading test user: %v", err)
+	}
+	return user.Id
+}
+
+func feedContains(t *testing.T, viewerId, postId int) bool {
+	t.Helper()
+	posts, err := ReadAllPosts(viewerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, post := range posts {
+		if post.Id == postId {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReadAllPostsFollowedTag(t *testing.T) {
+	openTestDB(t)
+	viewerId := createTestUser(t, "viewer@test.com")
+	authorId := createTestUser(t, "author@test.com")
+
+	post, err := AddPost(structs.Post{UserId: authorId, Title: "Go", Content: "all about #golang", Privacy: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertPostTags(post.Id, []string{"golang"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := FollowTag(viewerId, "golang"); err != nil {
+		t.Fatal(err)
+	}
+	if !feedContains(t, viewerId, post.Id) {
+		t.Fatal("post tagged with a followed tag is missing from the feed")
+	}
+}
+
+func TestReadAllPostsPublicPostsOfAnyone(t *testing.T) {
+	openTestDB(t)
+	viewerId := createTestUser(t, "viewer@test.com")
+	authorId := createTestUser(t, "author@test.com")
+
+	post, err := AddPost(structs.Post{UserId: authorId, Title: "Hello", Content: "hello", Privacy: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !feedContains(t, viewerId, post.Id) {
+		t.Fatal("public post of an author the viewer doesn't follow is missing from the feed")
+	}
+}
+
+func TestReadAllPostsFollowedTagSkipsPrivatePosts(t *testing.T) {
+	openTestDB(t)
+	viewerId := createTestUser(t, "viewer@test.com")
+	authorId := createTestUser(t, "author@test.com")
+
+	post, err := AddPost(structs.Post{UserId: authorId, Title: "Go", Content: "#golang", Privacy: "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertPostTags(post.Id, []string{"golang"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := FollowTag(viewerId, "golang"); err != nil {
+		t.Fatal(err)
+	}
+
+	if feedContains(t, viewerId, post.Id) {
+		t.Fatal("followers-only post reached a viewer who only follows its tag")
+	}
+}
+
+func TestReadAllPostsOwnAndFollowedAuthors(t *testing.T) {
+	openTestDB(t)
+	viewerId := createTestUser(t, "viewer@test.com")
+	authorId := createTestUser(t, "author@test.com")
+
+	own, err := AddPost(structs.Post{UserId: viewerId, Title: "Mine", Content: "mine", Privacy: "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	followed, err := AddPost(structs.Post{UserId: authorId, Title: "Theirs", Content: "theirs", Privacy: "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DB.Exec(`INSERT INTO user_following (follower_id, following_id) VALUES (?, ?)`, viewerId, authorId); err != nil {
+		t.Fatal(err)
+	}
+
+	if !feedContains(t, viewerId, own.Id) {
+		t.Fatal("the viewer's own post is missing from the feed")
+	}
+	if !feedContains(t, viewerId, followed.Id) {
+		t.Fatal("followers-only post of a followed author is missing from the feed")
+	}
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"social-network/structs"
+	"testing"
+)
+
+func createTestInviteLink(t *testing.T, groupId, maxUses int) int {
+	t.Helper()
+	linkId, err := InsertGroupInviteLink(structs.GroupInviteLink{GroupId: groupId, Token: "token", CreatedBy: 1, MaxUses: maxUses})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return linkId
+}
+
+func TestJoinGroupWithInviteLinkStopsWhenUsedUp(t *testing.T) {
+	openTestDB(t)
+	firstId := createTestUser(t, "first@test.com")
+	secondId := createTestUser(t, "second@test.com")
+	linkId := createTestInviteLink(t, 1, 1)
+
+	used, err := JoinGroupWithInviteLink(linkId, firstId, 1)
+	if err != nil || !used {
+		t.Fatalf("first use: used = %v, err = %v", used, err)
+	}
+	used, err = JoinGroupWithInviteLink(linkId, secondId, 1)
+	if err != nil || used {
+		t.Fatalf("use past the limit: used = %v, err = %v", used, err)
+	}
+
+	for userId, want := range map[int]bool{firstId: true, secondId: false} {
+		isMember, err := CheckUserIfMemberOfGroup(userId, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isMember != want {
+			t.Errorf("user %d member = %v, want %v", userId, isMember, want)
+		}
+	}
+	link, err := GetGroupInviteLinkById(linkId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Uses != 1 {
+		t.Fatalf("uses = %d, want 1", link.Uses)
+	}
+}
+
+func TestRequestToJoinWithRevokedInviteLink(t *testing.T) {
+	openTestDB(t)
+	userId := createTestUser(t, "user@test.com")
+	linkId := createTestInviteLink(t, 1, 0)
+	if err := RevokeGroupInviteLink(linkId); err != nil {
+		t.Fatal(err)
+	}
+
+	used, err := RequestToJoinWithInviteLink(linkId, userId, 1, nil)
+	if err != nil || used {
+		t.Fatalf("used = %v, err = %v", used, err)
+	}
+	status, err := GetJoinRequestStatus(userId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "" {
+		t.Fatalf("a join request was sent through a revoked link, status %q", status)
+	}
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS post_tags (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    tag             TEXT,
+    post_id         INTEGER,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE TABLE IF NOT EXISTS group_post_tags (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    tag             TEXT,
+    group_post_id   INTEGER,
+    group_id        INTEGER,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_post_id) REFERENCES group_posts (id),
+    FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+
+CREATE TABLE IF NOT EXISTS tag_followers (
+    user_id         INTEGER,
+    tag             TEXT,
+    PRIMARY KEY (user_id, tag),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag ON post_tags (tag);
+CREATE INDEX IF NOT EXISTS idx_group_post_tags_tag ON group_post_tags (tag);
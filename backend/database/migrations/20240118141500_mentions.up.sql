@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS mentions (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    user_id             INTEGER,
+    sender_id           INTEGER,
+    content_type        TEXT,
+    content_id          INTEGER,
+    post_id             INTEGER,
+    group_id            INTEGER,
+    private_chat_id     INTEGER,
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (sender_id) REFERENCES users (id)
+);
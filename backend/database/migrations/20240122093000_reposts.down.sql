@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posts_repost_of;
+ALTER TABLE posts DROP COLUMN repost_of;
@@ -0,0 +1,3 @@
+ALTER TABLE posts ADD COLUMN repost_of INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_repost_of ON posts (repost_of);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS bookmark_collections (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    user_id         INTEGER,
+    name            TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    user_id         INTEGER,
+    collection_id   INTEGER,
+    post_id         INTEGER DEFAULT 0,
+    group_post_id   INTEGER DEFAULT 0,
+    position        INTEGER,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (collection_id, post_id, group_post_id),
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (collection_id) REFERENCES bookmark_collections (id)
+);
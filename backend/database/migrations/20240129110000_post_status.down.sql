@@ -0,0 +1,5 @@
+ALTER TABLE posts DROP COLUMN status;
+ALTER TABLE posts DROP COLUMN publish_at;
+
+ALTER TABLE group_posts DROP COLUMN status;
+ALTER TABLE group_posts DROP COLUMN publish_at;
@@ -0,0 +1,5 @@
+ALTER TABLE posts ADD COLUMN status TEXT DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN publish_at TIMESTAMP;
+
+ALTER TABLE group_posts ADD COLUMN status TEXT DEFAULT 'published';
+ALTER TABLE group_posts ADD COLUMN publish_at TIMESTAMP;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS polls (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    post_id         INTEGER UNIQUE,
+    multiple_choice BOOLEAN DEFAULT FALSE,
+    show_results    TEXT DEFAULT 'after_vote',
+    closes_at       TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts (id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    poll_id         INTEGER,
+    position        INTEGER,
+    text            TEXT,
+    FOREIGN KEY (poll_id) REFERENCES polls (id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id         INTEGER,
+    option_id       INTEGER,
+    user_id         INTEGER,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (poll_id, option_id, user_id),
+    FOREIGN KEY (poll_id) REFERENCES polls (id),
+    FOREIGN KEY (option_id) REFERENCES poll_options (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_user ON poll_votes (poll_id, user_id);
@@ -0,0 +1,5 @@
+ALTER TABLE comments ADD COLUMN parent_id INTEGER DEFAULT 0;
+ALTER TABLE group_comments ADD COLUMN parent_id INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_group_comments_parent_id ON group_comments (parent_id);
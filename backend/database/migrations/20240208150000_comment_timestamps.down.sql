@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS group_comment_reactions;
+DROP TABLE IF EXISTS comment_reactions;
+ALTER TABLE group_comments DROP COLUMN edited_at;
+ALTER TABLE group_comments DROP COLUMN created_at;
+ALTER TABLE comments DROP COLUMN edited_at;
+ALTER TABLE comments DROP COLUMN created_at;
@@ -0,0 +1,28 @@
+ALTER TABLE comments ADD COLUMN created_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+ALTER TABLE group_comments ADD COLUMN created_at TIMESTAMP;
+ALTER TABLE group_comments ADD COLUMN edited_at TIMESTAMP;
+
+-- sqlite can't add a column with a CURRENT_TIMESTAMP default, so existing comments get the migration time
+UPDATE comments SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+UPDATE group_comments SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    comment_id      INTEGER,
+    user_id         INTEGER,
+    reaction        TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE IF NOT EXISTS group_comment_reactions (
+    comment_id      INTEGER,
+    user_id         INTEGER,
+    reaction        TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES group_comments (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
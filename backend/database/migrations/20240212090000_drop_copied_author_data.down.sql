@@ -0,0 +1,15 @@
+ALTER TABLE posts ADD COLUMN user_avatar TEXT;
+ALTER TABLE comments ADD COLUMN user_avatar TEXT;
+ALTER TABLE comments ADD COLUMN creator_name TEXT;
+ALTER TABLE group_posts ADD COLUMN user_avatar VARCHAR(255);
+ALTER TABLE group_comments ADD COLUMN user_avatar TEXT;
+ALTER TABLE group_comments ADD COLUMN creator_name TEXT;
+
+UPDATE posts SET user_avatar = (SELECT avatar FROM users WHERE users.id = posts.user_id);
+UPDATE group_posts SET user_avatar = (SELECT avatar FROM users WHERE users.id = group_posts.user_id);
+UPDATE comments SET
+    user_avatar = (SELECT avatar FROM users WHERE users.id = comments.user_id),
+    creator_name = (SELECT CASE WHEN COALESCE(nickname, '') = '' THEN first_name || ' ' || last_name ELSE nickname END FROM users WHERE users.id = comments.user_id);
+UPDATE group_comments SET
+    user_avatar = (SELECT avatar FROM users WHERE users.id = group_comments.user_id),
+    creator_name = (SELECT CASE WHEN COALESCE(nickname, '') = '' THEN first_name || ' ' || last_name ELSE nickname END FROM users WHERE users.id = group_comments.user_id);
@@ -0,0 +1,75 @@
+-- author name and avatar are read from users now. The columns are part of foreign keys,
+-- which sqlite can't drop, so the tables are rebuilt without them.
+
+CREATE TABLE posts_new (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    user_id         INTEGER,
+    title           TEXT,
+    content         TEXT,
+    photo           TEXT,
+    privacy         TEXT,
+    repost_of       INTEGER DEFAULT 0,
+    status          TEXT DEFAULT 'published',
+    publish_at      TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+INSERT INTO posts_new (id, user_id, title, content, photo, privacy, repost_of, status, publish_at)
+SELECT id, user_id, title, content, photo, privacy, repost_of, status, publish_at FROM posts;
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
+CREATE INDEX IF NOT EXISTS idx_posts_repost_of ON posts (repost_of);
+
+CREATE TABLE comments_new (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    post_id         INTEGER,
+    user_id         INTEGER,
+    content         TEXT,
+    photo           TEXT,
+    parent_id       INTEGER DEFAULT 0,
+    created_at      TIMESTAMP,
+    edited_at       TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts (id),
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+INSERT INTO comments_new (id, post_id, user_id, content, photo, parent_id, created_at, edited_at)
+SELECT id, post_id, user_id, content, photo, parent_id, created_at, edited_at FROM comments;
+DROP TABLE comments;
+ALTER TABLE comments_new RENAME TO comments;
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+
+CREATE TABLE group_posts_new (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    group_id        INTEGER,
+    user_id         INTEGER,
+    title           TEXT,
+    content         TEXT,
+    photo           TEXT,
+    status          TEXT DEFAULT 'published',
+    publish_at      TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+INSERT INTO group_posts_new (id, group_id, user_id, title, content, photo, status, publish_at)
+SELECT id, group_id, user_id, title, content, photo, status, publish_at FROM group_posts;
+DROP TABLE group_posts;
+ALTER TABLE group_posts_new RENAME TO group_posts;
+
+CREATE TABLE group_comments_new (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    post_id         INTEGER,
+    user_id         INTEGER,
+    group_id        INTEGER,
+    content         TEXT,
+    photo           TEXT,
+    parent_id       INTEGER DEFAULT 0,
+    created_at      TIMESTAMP,
+    edited_at       TIMESTAMP,
+    FOREIGN KEY (post_id) REFERENCES posts (id),
+    FOREIGN KEY (user_id) REFERENCES users (id),
+    FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+INSERT INTO group_comments_new (id, post_id, user_id, group_id, content, photo, parent_id, created_at, edited_at)
+SELECT id, post_id, user_id, group_id, content, photo, parent_id, created_at, edited_at FROM group_comments;
+DROP TABLE group_comments;
+ALTER TABLE group_comments_new RENAME TO group_comments;
+CREATE INDEX IF NOT EXISTS idx_group_comments_parent_id ON group_comments (parent_id);
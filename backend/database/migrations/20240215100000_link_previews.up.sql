@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS link_previews (
+    url             TEXT PRIMARY KEY,
+    title           TEXT,
+    description     TEXT,
+    image           TEXT,
+    site_name       TEXT,
+    fetched_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
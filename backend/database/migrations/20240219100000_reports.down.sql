@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_reports_content;
+DROP INDEX IF EXISTS idx_reports_status;
+DROP TABLE IF EXISTS reports;
+
+-- hidden posts go back to being drafts of their authors
+UPDATE posts SET status = 'draft' WHERE status = 'hidden';
+UPDATE group_posts SET status = 'draft' WHERE status = 'hidden';
+
+ALTER TABLE chat_messages DROP COLUMN hidden;
+ALTER TABLE group_comments DROP COLUMN hidden;
+ALTER TABLE comments DROP COLUMN hidden;
+
+ALTER TABLE users DROP COLUMN suspended_until;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,30 @@
+-- moderators and admins are appointed by setting the role by hand, every other user is 'user'
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP;
+
+ALTER TABLE comments ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE group_comments ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE chat_messages ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS reports (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    reporter_id         INTEGER,
+    content_type        TEXT,
+    content_id          INTEGER,
+    reported_user_id    INTEGER,
+    reason              TEXT,
+    details             TEXT,
+    content_snapshot    TEXT,
+    status              TEXT DEFAULT 'open',
+    assignee_id         INTEGER DEFAULT 0,
+    action              TEXT DEFAULT '',
+    resolution_note     TEXT DEFAULT '',
+    created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at         TIMESTAMP,
+    resolved_by         INTEGER DEFAULT 0,
+    FOREIGN KEY (reporter_id) REFERENCES users (id),
+    FOREIGN KEY (reported_user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports (status, id);
+CREATE INDEX IF NOT EXISTS idx_reports_content ON reports (content_type, content_id);
@@ -0,0 +1,2 @@
+ALTER TABLE group_posts DROP COLUMN pinned_at;
+ALTER TABLE posts DROP COLUMN pinned_at;
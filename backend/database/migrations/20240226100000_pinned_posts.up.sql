@@ -0,0 +1,2 @@
+ALTER TABLE posts ADD COLUMN pinned_at TIMESTAMP;
+ALTER TABLE group_posts ADD COLUMN pinned_at TIMESTAMP;
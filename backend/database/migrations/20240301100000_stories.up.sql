@@ -0,0 +1,24 @@
+-- privacy follows posts: 'public', 'private' for followers or a comma separated list of user ids.
+-- media is the file name of the image under static/images/stories, it's deleted with the story.
+CREATE TABLE IF NOT EXISTS stories (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT UNIQUE,
+    user_id         INTEGER,
+    content         TEXT DEFAULT '',
+    media           TEXT DEFAULT '',
+    privacy         TEXT,
+    created_at      TIMESTAMP,
+    expires_at      TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stories_user ON stories (user_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_stories_expires_at ON stories (expires_at);
+
+CREATE TABLE IF NOT EXISTS story_views (
+    story_id        INTEGER,
+    viewer_id       INTEGER,
+    viewed_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (story_id, viewer_id),
+    FOREIGN KEY (story_id) REFERENCES stories (id),
+    FOREIGN KEY (viewer_id) REFERENCES users (id)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_group_members_group;
+ALTER TABLE group_members DROP COLUMN role;
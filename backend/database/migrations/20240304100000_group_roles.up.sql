@@ -0,0 +1,11 @@
+-- every member is 'member', 'moderator', 'admin' or 'owner', the owner is the group's creator_id
+ALTER TABLE group_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+INSERT INTO group_members (group_id, requester_id)
+SELECT g.id, g.creator_id FROM groups g
+WHERE NOT EXISTS(SELECT 1 FROM group_members gm WHERE gm.group_id = g.id AND gm.requester_id = g.creator_id);
+
+UPDATE group_members SET role = 'owner'
+WHERE requester_id = (SELECT creator_id FROM groups WHERE groups.id = group_members.group_id);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_group ON group_members (group_id, requester_id);
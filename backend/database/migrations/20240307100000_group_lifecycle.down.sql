@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN pending_owner_id;
+ALTER TABLE groups DROP COLUMN archived_at;
@@ -0,0 +1,4 @@
+-- archived groups stay readable, but nothing can be posted, joined or changed until they are unarchived
+ALTER TABLE groups ADD COLUMN archived_at TIMESTAMP;
+-- the member the owner offered the group to, 0 when no transfer is waiting to be accepted
+ALTER TABLE groups ADD COLUMN pending_owner_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- public groups can be read and joined by anyone, private ones are listed but their content is for members
+-- and joining needs approval, secret ones are only visible to their members and can only be joined by invitation
+ALTER TABLE groups ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private';
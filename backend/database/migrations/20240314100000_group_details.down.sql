@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN rules;
+ALTER TABLE groups DROP COLUMN cover;
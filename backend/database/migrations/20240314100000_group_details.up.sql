@@ -0,0 +1,3 @@
+-- file name of the cover image under static/images/groups, empty when the group has none
+ALTER TABLE groups ADD COLUMN cover TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN rules TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS group_invite_link_uses;
+DROP INDEX IF EXISTS idx_group_invite_links_group;
+DROP TABLE IF EXISTS group_invite_links;
@@ -0,0 +1,29 @@
+-- shareable links into a group, max_uses 0 means no limit and a NULL expires_at never expires
+CREATE TABLE group_invite_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    created_by INTEGER NOT NULL,
+    auto_approve INTEGER NOT NULL DEFAULT 0,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    uses INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups(id),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_group_invite_links_group ON group_invite_links (group_id);
+
+-- who used each link, status is "joined" for links that auto-approve and "requested" for the others
+-- until the join request is accepted ("joined") or declined
+CREATE TABLE group_invite_link_uses (
+    link_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (link_id, user_id),
+    FOREIGN KEY (link_id) REFERENCES group_invite_links(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_join_requests_status;
+ALTER TABLE join_requests DROP COLUMN responded_at;
+ALTER TABLE join_requests DROP COLUMN kind;
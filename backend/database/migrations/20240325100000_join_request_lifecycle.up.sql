@@ -0,0 +1,12 @@
+-- "request" when the user asked to join, "invitation" when a member invited them
+ALTER TABLE join_requests ADD COLUMN kind TEXT NOT NULL DEFAULT 'request';
+-- when the request was accepted, declined, withdrawn or expired
+ALTER TABLE join_requests ADD COLUMN responded_at TIMESTAMP;
+
+UPDATE join_requests SET kind = 'invitation'
+WHERE EXISTS (
+    SELECT 1 FROM group_notifications gn
+    WHERE gn.group_id = join_requests.group_id AND gn.receiver_id = join_requests.requester_id AND gn.type = 'invite_group_request'
+);
+
+CREATE INDEX idx_join_requests_status ON join_requests (status, created_at);
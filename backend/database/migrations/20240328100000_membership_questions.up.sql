@@ -0,0 +1,13 @@
+-- questions applicants answer when they ask to join the group, at most 5 per group
+CREATE TABLE group_questions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    FOREIGN KEY (group_id) REFERENCES groups(id)
+);
+
+CREATE INDEX idx_group_questions_group ON group_questions (group_id, position);
+
+-- JSON list of the questions asked and the applicant's answers, kept as they were when the request was sent
+ALTER TABLE join_requests ADD COLUMN answers TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,3 @@
+UPDATE group_posts SET status = 'published' WHERE status = 'pending';
+UPDATE group_posts SET status = 'hidden' WHERE status = 'rejected';
+ALTER TABLE groups DROP COLUMN post_approval;
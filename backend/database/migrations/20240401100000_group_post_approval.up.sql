@@ -0,0 +1,2 @@
+-- when set, new posts of members below moderator wait in the 'pending' status until a moderator approves them
+ALTER TABLE groups ADD COLUMN post_approval INTEGER NOT NULL DEFAULT 0;
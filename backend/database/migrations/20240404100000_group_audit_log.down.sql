@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS group_audit_log_no_update;
+DROP INDEX IF EXISTS idx_group_audit_log_group;
+DROP TABLE IF EXISTS group_audit_log;
@@ -0,0 +1,22 @@
+-- membership and moderation changes in each group, shown to the group's admins
+CREATE TABLE IF NOT EXISTS group_audit_log (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id        INTEGER NOT NULL,
+    actor_id        INTEGER NOT NULL,
+    action          TEXT NOT NULL,
+    target_type     TEXT NOT NULL DEFAULT '',
+    target_id       INTEGER NOT NULL DEFAULT 0,
+    details         TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (group_id) REFERENCES groups (id),
+    FOREIGN KEY (actor_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_audit_log_group ON group_audit_log (group_id, id);
+
+-- entries can't be rewritten, they are only deleted together with their group
+CREATE TRIGGER IF NOT EXISTS group_audit_log_no_update
+BEFORE UPDATE ON group_audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit log entries can not be changed');
+END;
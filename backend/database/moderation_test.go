@@ -0,0 +1,38 @@
+package database
+
+import (
+	"social-network/structs"
+	"testing"
+)
+
+func TestGetCommentByIdIncludingHidden(t *testing.T) {
+	openTestDB(t)
+	authorId := createTestUser(t, "author@test.com")
+
+	post, err := AddPost(structs.Post{UserId: authorId, Title: "Post", Content: "content", Privacy: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, commentId, err := InsertComment(structs.Comment{PostId: post.Id, UserId: authorId, Content: "comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HideContent("comment", commentId); err != nil {
+		t.Fatal(err)
+	}
+
+	comment, err := GetCommentById(commentId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment != nil {
+		t.Fatal("hidden comment is returned by GetCommentById")
+	}
+	comment, err = GetCommentByIdIncludingHidden(commentId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment == nil || comment.UserId != authorId {
+		t.Fatal("hidden comment is missing from GetCommentByIdIncludingHidden")
+	}
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var userRoles = map[string]bool{
+	"user":      true,
+	"moderator": true,
+	"admin":     true,
+}
+
+// authenticateAdmin is AuthenticateUserAndGetId for the /admin API, only admins get through
+func authenticateAdmin(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return 0, false
+	}
+
+	role, err := database.GetUserRole(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if role != "admin" {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return 0, false
+	}
+	return userId, true
+}
+
+// recordAudit writes an admin or moderator action to the audit log. The action has already happened
+// when this is called, so a failure is logged rather than reported to the caller.
+func recordAudit(actorId int, action, targetType string, targetId int, details string) {
+	err := database.InsertAuditLogEntry(structs.AuditLogEntry{
+		ActorId:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Details:    details,
+	})
+	if err != nil {
+		log.Println("Error writing audit log:", err)
+	}
+}
+
+// AdminReadUsers lists users matching ?q= (names, nickname or email) and optionally ?role=
+func AdminReadUsers(w http.ResponseWriter, r *http.Request) {
+	_, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	role := r.URL.Query().Get("role")
+	if role != "" && !userRoles[role] {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	users, err := database.SearchUsersForAdmin(strings.TrimSpace(r.URL.Query().Get("q")), role, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func AdminSetUserRole(w http.ResponseWriter, r *http.Request) {
+	adminId, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	user, ok := getAdminTargetUser(w, r, adminId)
+	if !ok {
+		return
+	}
+
+	var change structs.RoleChange
+	if err := helpers.DecodeJSONBody(r, &change); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if !userRoles[change.Role] {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	if err := database.SetUserRole(user.Id, change.Role); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(adminId, "set_role", "user", user.Id, user.Role+" -> "+change.Role)
+
+	helpers.ReturnMessageJSON(w, "Role updated", http.StatusOK, "success")
+}
+
+func AdminSuspendUser(w http.ResponseWriter, r *http.Request) {
+	adminId, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	user, ok := getAdminTargetUser(w, r, adminId)
+	if !ok {
+		return
+	}
+	if user.Role == "admin" {
+		helpers.ReturnMessageJSON(w, "Admins can't be suspended", http.StatusBadRequest, "error")
+		return
+	}
+
+	var suspension structs.Suspension
+	if err := helpers.DecodeJSONBody(r, &suspension); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if suspension.Days < 1 || suspension.Days > 3650 {
+		http.Error(w, "Suspensions last between 1 and 3650 days", http.StatusBadRequest)
+		return
+	}
+	suspension.Reason = strings.TrimSpace(suspension.Reason)
+
+	until := time.Now().AddDate(0, 0, suspension.Days)
+	if err := database.SuspendUser(user.Id, until); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(adminId, "suspend_user", "user", user.Id, strconv.Itoa(suspension.Days)+" days: "+suspension.Reason)
+
+	helpers.ReturnMessageJSON(w, "User suspended", http.StatusOK, "success")
+}
+
+func AdminUnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	adminId, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	user, ok := getAdminTargetUser(w, r, adminId)
+	if !ok {
+		return
+	}
+
+	if err := database.UnsuspendUser(user.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(adminId, "unsuspend_user", "user", user.Id, "")
+
+	helpers.ReturnMessageJSON(w, "User unsuspended", http.StatusOK, "success")
+}
+
+// AdminLogoutUser ends every session of the user
+func AdminLogoutUser(w http.ResponseWriter, r *http.Request) {
+	adminId, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	user, ok := getAdminTargetUser(w, r, adminId)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteUserFromSessions(user.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(adminId, "force_logout", "user", user.Id, "")
+
+	helpers.ReturnMessageJSON(w, "User logged out", http.StatusOK, "success")
+}
+
+// AdminDeleteContent deletes a post, comment, group post, group comment or chat message for good.
+// Comments are deleted with their replies.
+func AdminDeleteContent(w http.ResponseWriter, r *http.Request) {
+	adminId, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	vars := mux.Vars(r)
+	contentType := vars["type"]
+	contentId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	var authorId int
+	var deleteErr error
+	switch contentType {
+	case "post":
+		post, err := database.GetPostById(contentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if post == nil {
+			http.Error(w, "Content not found", http.StatusNotFound)
+			return
+		}
+		authorId = post.UserId
+		deleteErr = database.DeletePost(contentId)
+
+	case "group_post":
+		post, err := database.GetGroupPostById(contentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if post == nil {
+			http.Error(w, "Content not found", http.StatusNotFound)
+			return
+		}
+		authorId = post.UserId
+		deleteErr = database.DeleteGroupPost(contentId)
+		if deleteErr == nil {
+			recordGroupAudit(post.GroupId, adminId, "post_deleted", "group_post", contentId, "author "+strconv.Itoa(authorId))
+		}
+
+	case "comment":
+		comment, err := database.GetCommentByIdIncludingHidden(contentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if comment == nil {
+			http.Error(w, "Content not found", http.StatusNotFound)
+			return
+		}
+		authorId = comment.UserId
+		deleteErr = database.DeleteCommentThread(contentId)
+
+	case "group_comment":
+		comment, err := database.GetGroupCommentByIdIncludingHidden(contentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if comment == nil {
+			http.Error(w, "Content not found", http.StatusNotFound)
+			return
+		}
+		authorId = comment.UserId
+		deleteErr = database.DeleteGroupCommentThread(contentId)
+
+	case "message":
+		message, err := database.GetChatMessageByIdIncludingHidden(contentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if message == nil {
+			http.Error(w, "Content not found", http.StatusNotFound)
+			return
+		}
+		authorId = message.SenderId
+		deleteErr = database.DeleteChatMessage(contentId)
+
+	default:
+		http.Error(w, "Invalid content type", http.StatusBadRequest)
+		return
+	}
+	if deleteErr != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(adminId, "delete_content", contentType, contentId, "author "+strconv.Itoa(authorId))
+
+	helpers.ReturnMessageJSON(w, "Content deleted", http.StatusOK, "success")
+}
+
+func AdminReadStats(w http.ResponseWriter, r *http.Request) {
+	_, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	stats, err := database.GetPlatformStats()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// AdminReadAuditLog lists the audit log newest first, ?actor= narrows it to one admin or moderator
+func AdminReadAuditLog(w http.ResponseWriter, r *http.Request) {
+	_, isAdmin := authenticateAdmin(w, r)
+	if !isAdmin {
+		return
+	}
+
+	actorId := 0
+	if actor := r.URL.Query().Get("actor"); actor != "" {
+		id, err := strconv.Atoi(actor)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid actor", http.StatusBadRequest)
+			return
+		}
+		actorId = id
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	entries, err := database.ReadAuditLog(actorId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// getAdminTargetUser reads the user from the URL, admins can't act on their own account
+// so they can't lock themselves out or drop their own role
+func getAdminTargetUser(w http.ResponseWriter, r *http.Request, adminId int) (*structs.User, bool) {
+	vars := mux.Vars(r)
+	userId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return nil, false
+	}
+	if userId == adminId {
+		helpers.ReturnMessageJSON(w, "You can't do this to your own account", http.StatusBadRequest, "error")
+		return nil, false
+	}
+
+	user, err := database.GetUserById(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if user == nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return nil, false
+	}
+	return user, true
+}
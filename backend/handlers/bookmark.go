@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func CreateBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	var collectionInfo structs.BookmarkCollection
+	if err := helpers.DecodeJSONBody(r, &collectionInfo); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	collectionInfo.Name = strings.TrimSpace(collectionInfo.Name)
+	if collectionInfo.Name == "" {
+		http.Error(w, "Please provide a collection name", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := database.CreateBookmarkCollection(userId, collectionInfo.Name)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collection)
+}
+
+func ReadBookmarkCollections(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	collections, err := database.GetBookmarkCollections(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collections)
+}
+
+func ReadBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	collection, ok := getOwnBookmarkCollection(w, r, userId)
+	if !ok {
+		return
+	}
+
+	bookmarks, err := database.ReadBookmarks(collection.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// access is checked again on every read, the user may have unfollowed someone or left a group since saving
+	visibleBookmarks := make([]structs.Bookmark, 0)
+	for _, bookmark := range bookmarks {
+		visible, err := loadBookmarkContent(userId, &bookmark)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if visible {
+			visibleBookmarks = append(visibleBookmarks, bookmark)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(visibleBookmarks)
+}
+
+func DeleteBookmarkCollection(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	collection, ok := getOwnBookmarkCollection(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteBookmarkCollection(collection.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Collection deleted", http.StatusOK, "success")
+}
+
+func ReorderBookmarks(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	collection, ok := getOwnBookmarkCollection(w, r, userId)
+	if !ok {
+		return
+	}
+
+	var request structs.ReorderBookmarks
+	if err := helpers.DecodeJSONBody(r, &request); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := database.ReorderBookmarks(collection.Id, request.BookmarkIds); err != nil {
+		helpers.ReturnMessageJSON(w, err.Error(), http.StatusBadRequest, "error")
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Bookmarks reordered", http.StatusOK, "success")
+}
+
+func AddBookmark(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	var bookmark structs.Bookmark
+	if err := helpers.DecodeJSONBody(r, &bookmark); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if (bookmark.PostId == 0) == (bookmark.GroupPostId == 0) {
+		http.Error(w, "Please provide either a post or a group post", http.StatusBadRequest)
+		return
+	}
+	bookmark.UserId = userId
+
+	if bookmark.CollectionId == 0 {
+		collectionId, err := database.GetOrCreateDefaultCollection(userId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		bookmark.CollectionId = collectionId
+	} else {
+		collection, err := database.GetBookmarkCollection(bookmark.CollectionId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if collection == nil || collection.UserId != userId {
+			http.Error(w, "Collection not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	visible, err := loadBookmarkContent(userId, &bookmark)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	exists, err := database.CheckBookmarkIfExists(bookmark.CollectionId, bookmark.PostId, bookmark.GroupPostId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if exists {
+		helpers.ReturnMessageJSON(w, "Post is already in this collection", http.StatusBadRequest, "error")
+		return
+	}
+
+	savedBookmark, err := database.InsertBookmark(bookmark)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	savedBookmark.Post = bookmark.Post
+	savedBookmark.GroupPost = bookmark.GroupPost
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(savedBookmark)
+}
+
+func RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookmarkId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+		return
+	}
+
+	bookmark, err := database.GetBookmarkById(bookmarkId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if bookmark == nil || bookmark.UserId != userId {
+		http.Error(w, "Bookmark not found", http.StatusNotFound)
+		return
+	}
+
+	if err := database.DeleteBookmark(bookmarkId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Bookmark removed", http.StatusOK, "success")
+}
+
+// getOwnBookmarkCollection reads the collection from the URL and makes sure it belongs to the user
+func getOwnBookmarkCollection(w http.ResponseWriter, r *http.Request, userId int) (*structs.BookmarkCollection, bool) {
+	vars := mux.Vars(r)
+	collectionId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	collection, err := database.GetBookmarkCollection(collectionId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	// someone else's collection is reported as missing so its existence isn't revealed
+	if collection == nil || collection.UserId != userId {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return nil, false
+	}
+	return collection, true
+}
+
+// loadBookmarkContent attaches the saved post to the bookmark if the user can still see it
+func loadBookmarkContent(userId int, bookmark *structs.Bookmark) (bool, error) {
+	if bookmark.PostId != 0 {
+		visible, err := database.IsPostVisibleToUser(bookmark.PostId, userId)
+		if err != nil || !visible {
+			return false, err
+		}
+		post, err := database.GetPostById(bookmark.PostId)
+		if err != nil || post == nil {
+			return false, err
+		}
+		if post.Privacy != "public" && post.Privacy != "private" {
+			post.Privacy = "private"
+		}
+		post.Comments, err = database.ReadAllComments(post.Id)
+		if err != nil {
+			return false, err
+		}
+		if err := attachPostDetail(post, userId); err != nil {
+			return false, err
+		}
+		bookmark.Post = post
+		return true, nil
+	}
+
+	post, err := database.GetGroupPostById(bookmark.GroupPostId)
+	if err != nil || post == nil || post.Status != "published" {
+		return false, err
+	}
+	readable, err := database.CanUserReadGroup(userId, post.GroupId)
+	if err != nil || !readable {
+		return false, err
+	}
+	post.Comments, err = database.ReadAllGroupComments(post.Id, post.GroupId)
+	if err != nil {
+		return false, err
+	}
+	renderGroupPostContent(post)
+	bookmark.GroupPost = post
+	return true, nil
+}
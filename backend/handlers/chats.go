@@ -53,7 +53,7 @@ func ChatDisplayHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func MessageHandler(w http.ResponseWriter, r *http.Request) {
-	_, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
 		return
 	}
@@ -66,11 +66,27 @@ func MessageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the group chat is for members only, whatever the visibility of the group
+	if chatType == "group" {
+		isMember, err := database.CheckUserIfMemberOfGroup(userId, chatId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !isMember {
+			helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+			return
+		}
+	}
+
 	messages, err := database.GetMessagesForUser(chatId, chatType)
 	if err != nil {
 		http.Error(w, "Failed to fetch messages", http.StatusInternalServerError)
 		return
 	}
+	for i := range messages {
+		messages[i].LinkPreview = cachedLinkPreview(messages[i].Content)
+	}
 
 	jsonMessages, err := json.Marshal(messages)
 	if err != nil {
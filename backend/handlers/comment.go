@@ -11,6 +11,9 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// MaxCommentDepth is how many levels of replies a comment can have, main overrides it from COMMENT_MAX_DEPTH
+var MaxCommentDepth = 3
+
 func CreateComment(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
@@ -39,7 +42,6 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 	} else {
 		newComment.CreatorName = UserInfo.Nickname
 	}
-	newComment.ProfilePicture = UserInfo.Avatar
 	vars := mux.Vars(r)
 	postId, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -48,11 +50,49 @@ func CreateComment(w http.ResponseWriter, r *http.Request) {
 	}
 	newComment.PostId = postId
 
-	comment, err := database.InsertComment(newComment)
+	var parent *structs.Comment
+	if newComment.ParentId != 0 {
+		parent, err = database.GetCommentById(newComment.ParentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if parent == nil || parent.PostId != postId {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			return
+		}
+		depth, err := database.GetCommentDepth(parent.Id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if depth >= MaxCommentDepth {
+			http.Error(w, "Replies can't be nested any deeper", http.StatusBadRequest)
+			return
+		}
+	}
+
+	comment, commentId, err := database.InsertComment(newComment)
 	if err != nil {
 		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
 		return
 	}
+	if parent != nil && parent.UserId != userId {
+		sendNotification(parent.UserId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  parent.UserId,
+			Content:     newComment.CreatorName + " replied to your comment",
+			Type:        "comment_reply",
+			Status:      "",
+		})
+	}
+	notifyMentions(newComment.Content, structs.Mention{
+		SenderId:    userId,
+		ContentType: "comment",
+		ContentId:   commentId,
+		PostId:      postId,
+	})
+	renderPostContent(&comment)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(comment); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -87,7 +127,6 @@ func CreateCommentInGroup(w http.ResponseWriter, r *http.Request) {
 	} else {
 		newCommentInGroup.CreatorName = UserInfo.Nickname
 	}
-	newCommentInGroup.ProfilePicture = UserInfo.Avatar
 
 	vars := mux.Vars(r)
 	postId, err := strconv.Atoi(vars["postId"])
@@ -113,14 +152,415 @@ func CreateCommentInGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "You aren't a member of this group", http.StatusBadRequest)
 		return
 	}
+	if !checkGroupNotArchived(w, groupId) {
+		return
+	}
+
+	var parent *structs.GroupComment
+	if newCommentInGroup.ParentId != 0 {
+		parent, err = database.GetGroupCommentById(newCommentInGroup.ParentId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if parent == nil || parent.PostId != postId || parent.GroupId != groupId {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			return
+		}
+		depth, err := database.GetGroupCommentDepth(parent.Id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if depth >= MaxCommentDepth {
+			http.Error(w, "Replies can't be nested any deeper", http.StatusBadRequest)
+			return
+		}
+	}
 
-	commentInGroup, err := database.InsertGroupComment(newCommentInGroup)
+	commentInGroup, commentId, err := database.InsertGroupComment(newCommentInGroup)
 	if err != nil {
 		http.Error(w, "Failed to create comment", http.StatusInternalServerError)
 		return
 	}
+	if parent != nil && parent.UserId != userId {
+		sendNotification(parent.UserId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  parent.UserId,
+			GroupId:     groupId,
+			Content:     newCommentInGroup.CreatorName + " replied to your comment",
+			Type:        "comment_reply",
+			Status:      "",
+		})
+	}
+	notifyMentions(newCommentInGroup.Content, structs.Mention{
+		SenderId:    userId,
+		ContentType: "group_comment",
+		ContentId:   commentId,
+		PostId:      postId,
+		GroupId:     groupId,
+	})
+	renderGroupPostContent(&commentInGroup)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(commentInGroup); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
-}
\ No newline at end of file
+}
+
+func ReadComments(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	visible, err := database.IsPostVisibleToUser(postId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+
+	sort, ok := getCommentSort(w, r, "newest")
+	if !ok {
+		return
+	}
+	limit, offset := helpers.GetPagination(r)
+	comments, err := database.ReadCommentsPage(postId, 0, sort, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderCommentsContent(comments)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+func ReadCommentReplies(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getVisibleComment(w, r, userId)
+	if !ok {
+		return
+	}
+
+	sort, ok := getCommentSort(w, r, "oldest")
+	if !ok {
+		return
+	}
+	limit, offset := helpers.GetPagination(r)
+	replies, err := database.ReadCommentsPage(comment.PostId, comment.Id, sort, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderCommentsContent(replies)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(replies)
+}
+
+func EditComment(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getVisibleComment(w, r, userId)
+	if !ok {
+		return
+	}
+	if comment.UserId != userId {
+		http.Error(w, "You can only edit your own comments", http.StatusForbidden)
+		return
+	}
+
+	var changes structs.Comment
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if changes.Content == "" {
+		http.Error(w, "Please provide a comment", http.StatusBadRequest)
+		return
+	}
+	if changes.Photo == "" {
+		changes.Photo = comment.Photo
+	}
+
+	if err := database.UpdateComment(comment.Id, changes.Content, changes.Photo); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	notifyNewMentions(comment.Content, changes.Content, structs.Mention{
+		SenderId:    userId,
+		ContentType: "comment",
+		ContentId:   comment.Id,
+		PostId:      comment.PostId,
+	})
+
+	updatedComment, err := database.GetCommentById(comment.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	updatedComment.ContentHTML = helpers.RenderMarkdown(updatedComment.Content)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedComment)
+}
+
+func ReadGroupComments(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	if !checkGroupReadable(w, userId, groupId) {
+		return
+	}
+
+	sort, ok := getCommentSort(w, r, "newest")
+	if !ok {
+		return
+	}
+	limit, offset := helpers.GetPagination(r)
+	comments, err := database.ReadGroupCommentsPage(postId, groupId, 0, sort, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderGroupCommentsContent(comments)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+func ReadGroupCommentReplies(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getReadableGroupComment(w, r, userId)
+	if !ok {
+		return
+	}
+
+	sort, ok := getCommentSort(w, r, "oldest")
+	if !ok {
+		return
+	}
+	limit, offset := helpers.GetPagination(r)
+	replies, err := database.ReadGroupCommentsPage(comment.PostId, comment.GroupId, comment.Id, sort, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderGroupCommentsContent(replies)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(replies)
+}
+
+func EditGroupComment(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getMemberGroupComment(w, r, userId)
+	if !ok {
+		return
+	}
+	if comment.UserId != userId {
+		http.Error(w, "You can only edit your own comments", http.StatusForbidden)
+		return
+	}
+	if !checkGroupNotArchived(w, comment.GroupId) {
+		return
+	}
+
+	var changes structs.GroupComment
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if changes.Content == "" {
+		http.Error(w, "Please provide a comment", http.StatusBadRequest)
+		return
+	}
+	if changes.Photo == "" {
+		changes.Photo = comment.Photo
+	}
+
+	if err := database.UpdateGroupComment(comment.Id, changes.Content, changes.Photo); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	notifyNewMentions(comment.Content, changes.Content, structs.Mention{
+		SenderId:    userId,
+		ContentType: "group_comment",
+		ContentId:   comment.Id,
+		PostId:      comment.PostId,
+		GroupId:     comment.GroupId,
+	})
+
+	updatedComment, err := database.GetGroupCommentById(comment.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	updatedComment.ContentHTML = helpers.RenderMarkdown(updatedComment.Content)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedComment)
+}
+
+// DeleteGroupComment deletes a group comment with its replies, authors can delete their own and group moderators anyone's
+func DeleteGroupComment(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getMemberGroupComment(w, r, userId)
+	if !ok {
+		return
+	}
+	if comment.UserId != userId {
+		if _, ok := checkGroupPermission(w, userId, comment.GroupId, "delete_content"); !ok {
+			return
+		}
+	}
+
+	if err := database.DeleteGroupCommentThread(comment.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if comment.UserId != userId {
+		notifyGroupContentRemoved(userId, comment.UserId, comment.GroupId, "Your comment")
+	}
+
+	helpers.ReturnMessageJSON(w, "Comment deleted", http.StatusOK, "success")
+}
+
+// getCommentSort reads the "sort" query parameter of comment reads
+func getCommentSort(w http.ResponseWriter, r *http.Request, defaultSort string) (string, bool) {
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+		return defaultSort, true
+	case "oldest", "newest", "most_reacted":
+		return sort, true
+	}
+	http.Error(w, "Invalid sort, use oldest, newest or most_reacted", http.StatusBadRequest)
+	return "", false
+}
+
+// getVisibleComment reads the comment from the URL and makes sure the user can see the post it belongs to
+func getVisibleComment(w http.ResponseWriter, r *http.Request, userId int) (*structs.Comment, bool) {
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+	commentId, err := strconv.Atoi(vars["commentId"])
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	visible, err := database.IsPostVisibleToUser(postId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	comment, err := database.GetCommentById(commentId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !visible || comment == nil || comment.PostId != postId {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return nil, false
+	}
+	return comment, true
+}
+
+// getMemberGroupComment reads the group comment from the URL and makes sure the user is a member of its group
+func getMemberGroupComment(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupComment, bool) {
+	return getGroupComment(w, r, userId, database.CheckUserIfMemberOfGroup)
+}
+
+// getReadableGroupComment reads the group comment from the URL and makes sure the user can read its group
+func getReadableGroupComment(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupComment, bool) {
+	return getGroupComment(w, r, userId, database.CanUserReadGroup)
+}
+
+func getGroupComment(w http.ResponseWriter, r *http.Request, userId int, allowed func(userId, groupId int) (bool, error)) (*structs.GroupComment, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+	commentId, err := strconv.Atoi(vars["commentId"])
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	isAllowed, err := allowed(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !isAllowed {
+		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+		return nil, false
+	}
+
+	comment, err := database.GetGroupCommentById(commentId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if comment == nil || comment.PostId != postId || comment.GroupId != groupId {
+		http.Error(w, "Comment not found", http.StatusNotFound)
+		return nil, false
+	}
+	return comment, true
+}
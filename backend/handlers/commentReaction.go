@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+)
+
+var commentReactions = map[string]bool{
+	"like":  true,
+	"love":  true,
+	"laugh": true,
+	"wow":   true,
+	"sad":   true,
+	"angry": true,
+}
+
+func ReactToComment(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getVisibleComment(w, r, userId)
+	if !ok {
+		return
+	}
+
+	reaction, ok := decodeCommentReaction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := database.SetCommentReaction(comment.Id, userId, reaction); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Reaction saved", http.StatusOK, "success")
+}
+
+func RemoveCommentReaction(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getVisibleComment(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteCommentReaction(comment.Id, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Reaction removed", http.StatusOK, "success")
+}
+
+func ReactToGroupComment(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getMemberGroupComment(w, r, userId)
+	if !ok || !checkGroupNotArchived(w, comment.GroupId) {
+		return
+	}
+
+	reaction, ok := decodeCommentReaction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := database.SetGroupCommentReaction(comment.Id, userId, reaction); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Reaction saved", http.StatusOK, "success")
+}
+
+func RemoveGroupCommentReaction(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	comment, ok := getMemberGroupComment(w, r, userId)
+	if !ok || !checkGroupNotArchived(w, comment.GroupId) {
+		return
+	}
+
+	if err := database.DeleteGroupCommentReaction(comment.Id, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Reaction removed", http.StatusOK, "success")
+}
+
+func decodeCommentReaction(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var reaction structs.CommentReaction
+	if err := helpers.DecodeJSONBody(r, &reaction); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return "", false
+	}
+	if !commentReactions[reaction.Reaction] {
+		http.Error(w, "Invalid reaction", http.StatusBadRequest)
+		return "", false
+	}
+	return reaction.Reaction, true
+}
@@ -8,6 +8,7 @@ import (
 	"social-network/helpers"
 	"social-network/structs"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -45,14 +46,11 @@ func CreateGroupEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if _, ok := checkGroupPermission(w, userId, groupId, "create_events"); !ok {
 		return
 	}
-
-	if !isMember {
-		http.Error(w, "You aren't a member of this group", http.StatusBadRequest)
+	if group.Archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
 		return
 	}
 
@@ -61,6 +59,7 @@ func CreateGroupEvent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to create event", http.StatusInternalServerError)
 		return
 	}
+	recordGroupAudit(groupId, userId, "event_created", "event", events.Id, events.Name)
 
 	for _, user := range group.Members {
 		notification := structs.Notification{
@@ -79,7 +78,9 @@ func CreateGroupEvent(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func ReadGroupEvents(w http.ResponseWriter, r *http.Request) {
+// EditGroupEvent changes the name, description or time of an event, anyone who can create events in the group can
+// edit them. The members are told about the change.
+func EditGroupEvent(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
 		return
@@ -91,15 +92,106 @@ func ReadGroupEvents(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid group ID", http.StatusBadRequest)
 		return
 	}
+	eventId, err := strconv.Atoi(vars["eventId"])
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "create_events"); !ok || !checkGroupNotArchived(w, groupId) {
+		return
+	}
 
-	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
+	event, err := database.GetGroupEventById(eventId)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if event == nil || event.GroupId != groupId {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
 
-	if !isMember {
-		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+	var changes structs.EventUpdate
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	var changed []string
+	if changes.Name != nil {
+		name := strings.TrimSpace(*changes.Name)
+		if name == "" {
+			http.Error(w, "Add event name and description", http.StatusBadRequest)
+			return
+		}
+		if name != event.Name {
+			changed = append(changed, "name")
+		}
+		event.Name = name
+	}
+	if changes.Description != nil {
+		description := strings.TrimSpace(*changes.Description)
+		if description == "" {
+			http.Error(w, "Add event name and description", http.StatusBadRequest)
+			return
+		}
+		if description != event.Description {
+			changed = append(changed, "description")
+		}
+		event.Description = description
+	}
+	if changes.Time != nil && *changes.Time != event.Time {
+		event.Time = *changes.Time
+		changed = append(changed, "time")
+	}
+	if len(changed) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(event)
+		return
+	}
+
+	if err := database.UpdateGroupEvent(*event); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(groupId, userId, "event_edited", "event", event.Id, event.Name+": "+strings.Join(changed, ", "))
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, memberId := range group.Members {
+		if memberId == userId {
+			continue
+		}
+		sendNotification(memberId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  memberId,
+			GroupId:     groupId,
+			Content:     "The event '" + event.Name + "' was changed in the group '" + group.Name + "'",
+			Type:        "event",
+			Status:      "",
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+func ReadGroupEvents(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if !checkGroupReadable(w, userId, groupId) {
 		return
 	}
 
@@ -113,6 +205,7 @@ func ReadGroupEvents(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(groupEvents)
 }
 
+// SelectEventOption saves the member's answer to an event of the group
 func SelectEventOption(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
@@ -126,6 +219,38 @@ func SelectEventOption(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	event, err := database.GetGroupEventById(eventOption.EventId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if event == nil || event.GroupId != groupId {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusForbidden, "error")
+		return
+	}
+	if !containsOption(event.Options, eventOption.Option) {
+		http.Error(w, "Choose one of the event's options", http.StatusBadRequest)
+		return
+	}
+	if !checkGroupNotArchived(w, event.GroupId) {
+		return
+	}
+
 	eventOption.UserId = userId
 	err = database.AddEventOptionChoice(eventOption)
 	if err != nil {
@@ -134,3 +259,12 @@ func SelectEventOption(w http.ResponseWriter, r *http.Request) {
 	}
 	helpers.ReturnMessageJSON(w, "Event choice has been successfully saved!", http.StatusOK, "success")
 }
+
+func containsOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"social-network/database"
+	"social-network/structs"
+	"testing"
+)
+
+func TestSelectEventOptionIsForMembers(t *testing.T) {
+	openTestDB(t)
+	event, err := database.AddGroupEvent(structs.Event{GroupId: 1, CreatorId: 1, Name: "Meetup", Description: "Monthly", Time: "2030-01-01 18:00", Options: []string{"Going", "Not going"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsiderId := createTestUser(t, "outsider@test.com")
+	choose := func(userId int, url string, option string) int {
+		choice := structs.EventOption{EventId: event.Id, Option: option}
+		return doTestRequest(t, SelectEventOption, "/group/{id}/event/choice", "POST", url, loginTestUser(t, userId), choice).Code
+	}
+
+	if code := choose(outsiderId, "/group/1/event/choice", "Going"); code != http.StatusForbidden {
+		t.Fatalf("an outsider answered an event of a private group, status %d", code)
+	}
+	// user 1 is also a member of group 2
+	if code := choose(1, "/group/2/event/choice", "Going"); code != http.StatusNotFound {
+		t.Fatalf("answered the event through another group, status %d", code)
+	}
+	if code := choose(2, "/group/1/event/choice", "Maybe"); code != http.StatusBadRequest {
+		t.Fatalf("saved an option the event doesn't have, status %d", code)
+	}
+	if code := choose(2, "/group/1/event/choice", "Going"); code != http.StatusOK {
+		t.Fatalf("member choice: status %d", code)
+	}
+
+	if err := database.SetGroupArchived(1, true); err != nil {
+		t.Fatal(err)
+	}
+	if code := choose(2, "/group/1/event/choice", "Not going"); code != http.StatusForbidden {
+		t.Fatalf("answered an event of an archived group, status %d", code)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"social-network/structs"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -30,6 +31,11 @@ func CreateGroup(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Add group name and description", http.StatusBadRequest)
 		return
 	}
+	creationGroupInfo.Visibility, err = normalizeGroupVisibility(creationGroupInfo.Visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	exists, err := database.CheckGroupNameIfExists(creationGroupInfo.Name)
 	if err != nil {
@@ -57,21 +63,25 @@ func CreateGroup(w http.ResponseWriter, r *http.Request) {
 }
 
 func ReadAllGroups(w http.ResponseWriter, r *http.Request) {
-	_, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
 		return
 	}
 
-	groups, err := database.ReadAllGroups()
+	groups, err := database.ReadAllGroups(userId)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 	}
+	for i := range groups {
+		setGroupCoverURL(&groups[i])
+		hideGroupMembers(&groups[i], containsId(groups[i].Members, userId))
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(groups)
 }
 
 func GroupHandler(w http.ResponseWriter, r *http.Request) {
-	_, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
 		return
 	}
@@ -79,15 +89,50 @@ func GroupHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	groupId, _ := strconv.Atoi(vars["id"])
 
+	// secret groups are reported as missing to everyone but their members and the users they invited
+	visible, err := database.CanUserSeeGroup(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
 	group, err := database.ReadGroup(groupId)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch group details: %v", err), http.StatusInternalServerError)
 		return
 	}
+	group.Role, err = database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	setGroupCoverURL(group)
+	hideGroupMembers(group, group.Role != "")
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(group)
 }
 
+// hideGroupMembers keeps who is in a group that isn't public, and who it's being handed over to, from users outside of it
+func hideGroupMembers(group *structs.Group, isMember bool) {
+	if !isMember && group.Visibility != "public" {
+		group.Members = nil
+		group.PendingOwnerId = 0
+	}
+}
+
+func containsId(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
 func SendJoinRequest(w http.ResponseWriter, r *http.Request) {
 	requesterID, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
@@ -118,6 +163,19 @@ func SendJoinRequest(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Group does not exist", http.StatusBadRequest)
 		return
 	}
+	visible, err := database.CanUserSeeGroup(requesterID, groupID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Group does not exist", http.StatusBadRequest)
+		return
+	}
+	if group.Archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
+		return
+	}
 
 	// Check if the requester is already a member of the group
 	isMember, err := database.CheckUserIfMemberOfGroup(requesterID, groupID)
@@ -129,51 +187,138 @@ func SendJoinRequest(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "You're already a member of this group", http.StatusBadRequest)
 		return
 	}
+	if !checkNotBannedFromGroup(w, requesterID, groupID) {
+		return
+	}
 
-	// Get the owner of the group
-	groupOwnerID, err := database.GetGroupOwner(groupID)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error getting group owner: %v", err), http.StatusInternalServerError)
+	switch group.Visibility {
+	case "secret":
+		helpers.ReturnMessageJSON(w, "This group can only be joined by invitation", http.StatusForbidden, "error")
+		return
+	case "public":
+		joinGroup(w, requesterID, creatorName, group)
 		return
 	}
 
-	// Check if a join request already exists
-	existingStatus, err := database.GetJoinRequestStatus(requesterID, groupID)
+	if !checkCanRequestToJoin(w, requesterID, groupID) {
+		return
+	}
+	answers, ok := readJoinRequestAnswers(w, r, groupID)
+	if !ok {
+		return
+	}
+	requestToJoinGroup(w, requesterID, creatorName, group, answers)
+}
+
+// checkCanRequestToJoin refuses a new join request when one is already waiting, or when the last one was declined
+// less than JoinRequestCooldown ago
+func checkCanRequestToJoin(w http.ResponseWriter, userId, groupId int) bool {
+	existing, err := database.GetJoinRequest(userId, groupId)
 	if err != nil {
 		http.Error(w, "Internal server error, GetJoinRequestStatus", http.StatusInternalServerError)
-		return
+		return false
+	}
+	if existing == nil {
+		return true
 	}
 
-	if existingStatus == "pending" {
+	if existing.Status == "pending" && existing.Kind == "invitation" {
+		helpers.ReturnMessageJSON(w, "You were invited to this group, accept the invitation to join", http.StatusBadRequest, "error")
+		return false
+	} else if existing.Status == "pending" {
 		helpers.ReturnMessageJSON(w, "Join request is already sent", http.StatusBadRequest, "error")
-		return
-	} else if existingStatus == "declined" {
-		helpers.ReturnMessageJSON(w, "Your request has already been declined", http.StatusBadRequest, "error")
-		return
+		return false
+	} else if existing.Status == "declined" && existing.Kind == "request" {
+		if reapplyAt := joinRequestReapplyTime(existing); time.Now().Before(reapplyAt) {
+			helpers.ReturnMessageJSON(w, "Your request has been declined, you can ask again after "+reapplyAt.Format("2 Jan 2006 15:04"), http.StatusBadRequest, "error")
+			return false
+		}
 	}
+	return true
+}
 
-	// Create a new join request only if the group exists
-	err = database.InsertJoinRequest(requesterID, groupID)
+// requestToJoinGroup creates a join request and notifies everyone who can approve it, the notification shows the
+// answers to the group's membership questions
+func requestToJoinGroup(w http.ResponseWriter, userId int, userName string, group *structs.Group, answers []structs.JoinRequestAnswer) {
+	err := database.InsertJoinRequest(userId, group.Id, "request", answers)
 	if err != nil {
 		helpers.ReturnMessageJSON(w, err.Error(), http.StatusBadRequest, "error")
 		return
 	}
+	announceJoinRequest(w, userId, userName, group, answers)
+}
 
-	// Send a notification to the group owner
-	notification := structs.Notification{
-		RequesterId: requesterID,
-		ReceiverId:  groupOwnerID,
-		GroupId:     groupID,
-		Content:     creatorName + " requested to join your group '" + group.Name + "'",
-		Type:        "join_request",
-		Status:      "pending",
+// announceJoinRequest tells everyone who can approve it about the join request the user just sent
+func announceJoinRequest(w http.ResponseWriter, userId int, userName string, group *structs.Group, answers []structs.JoinRequestAnswer) {
+	// Get everyone who can approve the request
+	approverIds, err := database.GetGroupMemberIdsByRole(group.Id, groupRolesAllowed("approve_join_requests")...)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting group approvers: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	// Send the notification via WebSocket
-	sendNotification(groupOwnerID, notification)
+	// Send a notification to the owner, admins and moderators
+	for _, approverId := range approverIds {
+		notification := structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  approverId,
+			GroupId:     group.Id,
+			Content:     withAnswers(userName+" requested to join your group '"+group.Name+"'", answers),
+			Type:        "join_request",
+			Status:      "pending",
+		}
+
+		// Send the notification via WebSocket
+		sendNotification(approverId, notification)
+	}
 	helpers.ReturnMessageJSON(w, "Join request is sent successfully!", http.StatusOK, "success")
 }
 
+// joinGroup adds the user to the group right away, an invitation or join request of theirs still waiting is accepted with it
+func joinGroup(w http.ResponseWriter, userId int, userName string, group *structs.Group) {
+	if err := database.AddUserToGroup(userId, group.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	announceGroupJoin(w, userId, userName, group)
+}
+
+// announceGroupJoin answers the invitation or join request the user who just joined still had waiting and tells the
+// group's owner
+func announceGroupJoin(w http.ResponseWriter, userId int, userName string, group *structs.Group) {
+	existingStatus, err := database.GetJoinRequestStatus(userId, group.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if existingStatus == "pending" {
+		invited, err := database.HasPendingGroupInvitation(userId, group.Id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		requestType := "join_request"
+		if invited {
+			requestType = "invite_group_request"
+		}
+		if err := database.RespondToJoinRequest(userId, group.Id, "accepted", requestType); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	recordGroupAudit(group.Id, userId, "member_joined", "user", userId, "")
+
+	sendNotification(group.CreatorId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  group.CreatorId,
+		GroupId:     group.Id,
+		Content:     userName + " joined your group '" + group.Name + "'",
+		Type:        "group_joined",
+		Status:      "",
+	})
+	helpers.ReturnMessageJSON(w, "You joined the group", http.StatusOK, "success")
+}
+
 func ExtractGroupId(r *http.Request) (int, error) {
 	vars := mux.Vars(r)
 	fmt.Println("Raw URL:", r.URL.String()) // Print the raw URL
@@ -204,15 +349,17 @@ func AcceptJoinRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the user is the owner of the group
 	group, err := database.ReadGroup(groupId)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch group details: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	if group.CreatorId != groupOwnerId {
-		http.Error(w, "You are not the owner of this group", http.StatusForbidden)
+	// Check if the user is allowed to approve join requests
+	if _, ok := checkGroupPermission(w, groupOwnerId, groupId, "approve_join_requests"); !ok {
+		return
+	}
+	if !checkGroupNotArchived(w, groupId) {
 		return
 	}
 
@@ -221,6 +368,9 @@ func AcceptJoinRequest(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Bad request, error 400", http.StatusBadRequest)
 		return
 	}
+	if !checkPendingJoinRequest(w, request.RequesterId, groupId) {
+		return
+	}
 
 	// Update the status of the join request to "accepted"
 	err = database.RespondToJoinRequest(request.RequesterId, groupId, "accepted", "join_request")
@@ -235,6 +385,10 @@ func AcceptJoinRequest(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if err := database.SetInviteLinkUseStatus(groupId, request.RequesterId, "joined"); err != nil {
+		log.Println("Error updating invite link use:", err)
+	}
+	recordGroupAudit(groupId, groupOwnerId, "member_joined", "user", request.RequesterId, "join request accepted")
 
 	// Send a notification to the user who sent the join request
 	notification := structs.Notification{
@@ -265,15 +419,14 @@ func DeclineJoinRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if the user is the owner of the group
 	group, err := database.ReadGroup(groupId)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to fetch group details: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	if group.CreatorId != groupOwnerId {
-		helpers.ReturnMessageJSON(w, "You are not the owner of this group", http.StatusForbidden, "error")
+	// Check if the user is allowed to decline join requests
+	if _, ok := checkGroupPermission(w, groupOwnerId, groupId, "approve_join_requests"); !ok {
 		return
 	}
 
@@ -282,6 +435,9 @@ func DeclineJoinRequest(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Bad request, error 400", http.StatusBadRequest)
 		return
 	}
+	if !checkPendingJoinRequest(w, request.RequesterId, groupId) {
+		return
+	}
 
 	// Update the status of the join request to "declined"
 	err = database.RespondToJoinRequest(request.RequesterId, groupId, "declined", "join_request")
@@ -290,13 +446,21 @@ func DeclineJoinRequest(w http.ResponseWriter, r *http.Request) {
 		helpers.ReturnMessageJSON(w, err.Error(), http.StatusBadRequest, "error")
 		return
 	}
+	if err := database.SetInviteLinkUseStatus(groupId, request.RequesterId, "declined"); err != nil {
+		log.Println("Error updating invite link use:", err)
+	}
+
+	content := "Owner " + group.Name + " group reject your request"
+	if JoinRequestCooldown > 0 {
+		content += ", you can ask again after " + time.Now().Add(JoinRequestCooldown).Format("2 Jan 2006 15:04")
+	}
 
 	// Send a notification to the user who sent the join request
 	notification := structs.Notification{
 		RequesterId: groupOwnerId,
 		ReceiverId:  request.RequesterId,
 		GroupId:     groupId,
-		Content:     "Owner " + group.Name + " group reject your request",
+		Content:     content,
 		Type:        "join_request",
 		Status:      "declined",
 	}
@@ -319,19 +483,30 @@ func LeaveGroup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the requester is a member of the group
-	isMember, err := database.CheckUserIfMemberOfGroup(requesterId, groupId)
+	role, err := database.GetGroupMemberRole(requesterId, groupId)
 	if err != nil {
-		http.Error(w, "Internal server error, can't check CheckUserIfMemberOfGroup", http.StatusInternalServerError)
+		http.Error(w, "Internal server error, can't check GetGroupMemberRole", http.StatusInternalServerError)
 		return
 	}
 
-	if isMember {
+	// the group can't be left without an owner
+	if role == "owner" {
+		helpers.ReturnMessageJSON(w, "Transfer the ownership or delete the group before leaving it", http.StatusBadRequest, "error")
+		return
+	}
+
+	if role != "" {
 		err = database.DeleteUserFromGroup(requesterId, groupId)
 		if err != nil {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
+		if group, err := database.ReadGroup(groupId); err == nil {
+			withdrawOwnershipOffer(group, requesterId)
+		}
+		recordGroupAudit(groupId, requesterId, "member_left", "user", requesterId, "")
+
 		helpers.ReturnMessageJSON(w, "You have left the group", http.StatusOK, "success")
 	}
 }
@@ -352,14 +527,12 @@ func InviteUsers(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Failed to fetch group details: %v", err), http.StatusInternalServerError)
 		return
 	}
-	// Check if the requester is a member of the group
-	isMember, err := database.CheckUserIfMemberOfGroup(requesterID, request.GroupId)
-	if err != nil {
-		http.Error(w, "Internal server error, can't check CheckUserIfMemberOfGroup", http.StatusInternalServerError)
+	// Check if the requester is allowed to invite members
+	if _, ok := checkGroupPermission(w, requesterID, request.GroupId, "invite_members"); !ok {
 		return
 	}
-	if !isMember {
-		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+	if group.Archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
 		return
 	}
 
@@ -380,12 +553,19 @@ func InviteUsers(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, "Internal server error, GetJoinRequestStatus", http.StatusInternalServerError)
 				return
 			}
-			if existingStatus == "pending" {
+			ban, err := database.GetActiveGroupBan(request.GroupId, user)
+			if err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if ban != nil {
+				errorResponse[user] = " is banned from this group. "
+			} else if existingStatus == "pending" {
 				errorResponse[user] = " has already been invited to the group or has requested to join. "
 			} else if existingStatus == "declined" {
 				errorResponse[user] = " declined invitation to the group or the owner declined the request to join for this user. "
 			} else {
-				err = database.InsertJoinRequest(user, request.GroupId)
+				err = database.InsertJoinRequest(user, request.GroupId, "invitation", nil)
 				if err != nil {
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
 					continue
@@ -464,6 +644,23 @@ func AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Failed to fetch group details: %v", err), http.StatusInternalServerError)
 		return
 	}
+	// an invitation is the only way into a secret group, so it has to be there to be accepted
+	invited, err := database.HasPendingGroupInvitation(userID, request.GroupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !invited {
+		helpers.ReturnMessageJSON(w, "You weren't invited to this group", http.StatusBadRequest, "error")
+		return
+	}
+	if !checkNotBannedFromGroup(w, userID, request.GroupId) {
+		return
+	}
+	if group.Archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
+		return
+	}
 	// Update the status of the join request to "accepted"
 	err = database.RespondToJoinRequest(userID, request.GroupId, "accepted", "invite_group_request")
 	if err != nil {
@@ -477,6 +674,7 @@ func AcceptInvitation(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	recordGroupAudit(request.GroupId, userID, "member_joined", "user", userID, "invitation accepted")
 
 	// Send a notification to the user who sent the join request
 	notification := structs.Notification{
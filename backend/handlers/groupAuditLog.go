@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// groupAuditActions are the actions written to the group audit log
+var groupAuditActions = map[string]bool{
+	"member_joined":         true,
+	"member_left":           true,
+	"member_removed":        true,
+	"member_banned":         true,
+	"member_unbanned":       true,
+	"role_changed":          true,
+	"ownership_transferred": true,
+	"post_deleted":          true,
+	"post_hidden":           true,
+	"post_approved":         true,
+	"post_rejected":         true,
+	"event_created":         true,
+	"event_edited":          true,
+	"settings_changed":      true,
+	"questions_changed":     true,
+	"group_archived":        true,
+	"group_unarchived":      true,
+	"invite_link_created":   true,
+	"invite_link_revoked":   true,
+}
+
+// recordGroupAudit writes a membership or moderation change to the group's audit log. Like recordAudit it's called
+// once the change is made, so a failure is only logged.
+func recordGroupAudit(groupId, actorId int, action, targetType string, targetId int, details string) {
+	err := database.InsertGroupAuditLogEntry(structs.GroupAuditLogEntry{
+		GroupId:    groupId,
+		ActorId:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Details:    details,
+	})
+	if err != nil {
+		log.Println("Error writing group audit log:", err)
+	}
+}
+
+// recordGroupPostHidden logs a group post a site moderator hid after a report in the audit log of its group
+func recordGroupPostHidden(actorId, postId int, note string) {
+	post, err := database.GetGroupPostById(postId)
+	if err != nil || post == nil {
+		log.Println("Error reading hidden group post:", err)
+		return
+	}
+	recordGroupAudit(post.GroupId, actorId, "post_hidden", "group_post", post.Id, withReason("author "+strconv.Itoa(post.UserId), note))
+}
+
+// ReadGroupAuditLog lists the group's audit log newest first, ?action=, ?actor=, ?targetType= and ?target=
+// narrow it down
+func ReadGroupAuditLog(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "view_audit_log"); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := structs.GroupAuditLogFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("targetType"),
+	}
+	if filter.Action != "" && !groupAuditActions[filter.Action] {
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+	if actor := query.Get("actor"); actor != "" {
+		filter.ActorId, err = strconv.Atoi(actor)
+		if err != nil || filter.ActorId <= 0 {
+			http.Error(w, "Invalid actor", http.StatusBadRequest)
+			return
+		}
+	}
+	if target := query.Get("target"); target != "" {
+		filter.TargetId, err = strconv.Atoi(target)
+		if err != nil || filter.TargetId <= 0 {
+			http.Error(w, "Invalid target", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	entries, err := database.ReadGroupAuditLog(groupId, filter, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
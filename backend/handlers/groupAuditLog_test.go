@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/structs"
+	"strconv"
+	"testing"
+)
+
+// expectAudited makes sure each of the actions was written to the group's audit log
+func expectAudited(t *testing.T, groupId int, actions ...string) {
+	t.Helper()
+	for _, action := range actions {
+		entries, err := database.ReadGroupAuditLog(groupId, structs.GroupAuditLogFilter{Action: action}, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			t.Errorf("%s is missing from the audit log", action)
+		}
+	}
+}
+
+func readTestAuditLog(t *testing.T, userId int, query string) []structs.GroupAuditLogEntry {
+	t.Helper()
+	response := doTestRequest(t, ReadGroupAuditLog, "/group/{id}/audit-log", "GET", "/group/1/audit-log"+query, loginTestUser(t, userId), nil)
+	expectStatus(t, response, http.StatusOK)
+	var entries []structs.GroupAuditLogEntry
+	if err := json.NewDecoder(response.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestReadGroupAuditLog(t *testing.T) {
+	openTestDB(t)
+	recordGroupAudit(1, 1, "member_removed", "user", 2, "")
+	recordGroupAudit(1, 1, "settings_changed", "group", 1, "name")
+
+	// admins only
+	expectStatus(t, doTestRequest(t, ReadGroupAuditLog, "/group/{id}/audit-log", "GET", "/group/1/audit-log", loginTestUser(t, 2), nil), http.StatusForbidden)
+	expectStatus(t, doTestRequest(t, ReadGroupAuditLog, "/group/{id}/audit-log", "GET", "/group/1/audit-log?action=unknown", loginTestUser(t, 1), nil), http.StatusBadRequest)
+
+	if entries := readTestAuditLog(t, 1, ""); len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	entries := readTestAuditLog(t, 1, "?action=member_removed")
+	if len(entries) != 1 || entries[0].TargetId != 2 {
+		t.Fatalf("filtered entries = %+v", entries)
+	}
+}
+
+func TestSiteModerationOfGroupPostsIsAudited(t *testing.T) {
+	openTestDB(t)
+	post, err := database.AddGroupPost(structs.GroupPost{GroupId: 1, UserId: 2, Title: "Reported", Content: "content", Status: "published"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := database.InsertReport(structs.Report{ReporterId: 3, ContentType: "group_post", ContentId: post.Id, ReportedUserId: 2, Reason: "spam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moderatorId := createTestUser(t, "moderator@test.com")
+	if err := database.SetUserRole(moderatorId, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	moderatorToken := loginTestUser(t, moderatorId)
+
+	resolveUrl := "/moderation/report/" + strconv.Itoa(report.Id) + "/resolve"
+	resolution := structs.ReportResolution{Action: "hide_content", Note: "off topic"}
+	expectStatus(t, doTestRequest(t, ResolveReport, "/moderation/report/{id}/resolve", "POST", resolveUrl, moderatorToken, resolution), http.StatusOK)
+
+	deleteUrl := "/admin/content/group_post/" + strconv.Itoa(post.Id)
+	expectStatus(t, doTestRequest(t, AdminDeleteContent, "/admin/content/{type}/{id}", "DELETE", deleteUrl, moderatorToken, nil), http.StatusOK)
+
+	for action, details := range map[string]string{"post_hidden": "author 2: off topic", "post_deleted": "author 2"} {
+		entries, err := database.ReadGroupAuditLog(1, structs.GroupAuditLogFilter{Action: action}, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("%d %s entries, want 1", len(entries), action)
+		}
+		if entries[0].ActorId != moderatorId || entries[0].TargetId != post.Id || entries[0].Details != details {
+			t.Errorf("%s entry = %+v", action, entries[0])
+		}
+	}
+}
+
+func TestEditGroupEventIsAudited(t *testing.T) {
+	openTestDB(t)
+	event, err := database.AddGroupEvent(structs.Event{GroupId: 1, CreatorId: 1, Name: "Meetup", Description: "Monthly", Time: "2030-01-01 18:00", Options: []string{"Going", "Not going"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherEvent, err := database.AddGroupEvent(structs.Event{GroupId: 2, CreatorId: 2, Name: "Reading", Description: "Books", Time: "2030-01-01 18:00", Options: []string{"Going"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit := func(eventId, userId int) int {
+		url := "/group/1/event/" + strconv.Itoa(eventId)
+		newTime := "2030-01-02 19:00"
+		return doTestRequest(t, EditGroupEvent, "/group/{id}/event/{eventId}", "PATCH", url, loginTestUser(t, userId), structs.EventUpdate{Time: &newTime}).Code
+	}
+
+	if code := edit(event.Id, 2); code != http.StatusForbidden {
+		t.Fatalf("a member edited the event, status %d", code)
+	}
+	if code := edit(otherEvent.Id, 1); code != http.StatusNotFound {
+		t.Fatalf("edited another group's event through this group, status %d", code)
+	}
+	if code := edit(event.Id, 1); code != http.StatusOK {
+		t.Fatalf("edit: status %d", code)
+	}
+
+	edited, err := database.GetGroupEventById(event.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Time != "2030-01-02 19:00" || edited.Name != "Meetup" || len(edited.Options) != 2 {
+		t.Fatalf("edited event = %+v", edited)
+	}
+	entries, err := database.ReadGroupAuditLog(1, structs.GroupAuditLogFilter{Action: "event_edited"}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].TargetId != event.Id || entries[0].Details != "Meetup: time" {
+		t.Fatalf("event_edited entries = %+v", entries)
+	}
+}
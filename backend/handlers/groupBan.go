@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxBanReasonLength = 500
+	maxGroupBanDays    = 3650
+)
+
+// RemoveGroupMember kicks a member out of the group, they can ask to join again later
+func RemoveGroupMember(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, memberId, ok := getManageableGroupMember(w, r, userId, "remove_members", true)
+	if !ok {
+		return
+	}
+	removal, ok := decodeMemberRemoval(w, r)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteUserFromGroup(memberId, group.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	withdrawOwnershipOffer(group, memberId)
+	recordGroupAudit(group.Id, userId, "member_removed", "user", memberId, removal.Reason)
+
+	sendNotification(memberId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  memberId,
+		GroupId:     group.Id,
+		Content:     withReason("You were removed from the group '"+group.Name+"'", removal.Reason),
+		Type:        "group_removed",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "Member removed", http.StatusOK, "success")
+}
+
+// BanGroupMember removes the user from the group and keeps them from joining again, for the given number of days
+// or for good. Users who aren't members can be banned too, their pending request or invitation is declined.
+func BanGroupMember(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, memberId, ok := getManageableGroupMember(w, r, userId, "ban_members", false)
+	if !ok {
+		return
+	}
+	removal, ok := decodeMemberRemoval(w, r)
+	if !ok {
+		return
+	}
+	if removal.Days < 0 || removal.Days > maxGroupBanDays {
+		http.Error(w, "Bans last between 1 and 3650 days, or 0 for a permanent ban", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if removal.Days > 0 {
+		until := time.Now().UTC().AddDate(0, 0, removal.Days)
+		expiresAt = &until
+	}
+	if err := database.BanGroupMember(group.Id, memberId, userId, removal.Reason, expiresAt); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	withdrawOwnershipOffer(group, memberId)
+
+	content := "You were banned from the group '" + group.Name + "'"
+	if expiresAt != nil {
+		content += " until " + expiresAt.Format("2 Jan 2006")
+	}
+	duration := "permanent"
+	if removal.Days > 0 {
+		duration = strconv.Itoa(removal.Days) + " days"
+	}
+	recordGroupAudit(group.Id, userId, "member_banned", "user", memberId, withReason(duration, removal.Reason))
+	sendNotification(memberId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  memberId,
+		GroupId:     group.Id,
+		Content:     withReason(content, removal.Reason),
+		Type:        "group_banned",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "User banned", http.StatusOK, "success")
+}
+
+func UnbanGroupMember(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	memberId, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "ban_members"); !ok {
+		return
+	}
+
+	unbanned, err := database.UnbanGroupMember(groupId, memberId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !unbanned {
+		http.Error(w, "Ban not found", http.StatusNotFound)
+		return
+	}
+	recordGroupAudit(groupId, userId, "member_unbanned", "user", memberId, "")
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	sendNotification(memberId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  memberId,
+		GroupId:     groupId,
+		Content:     "Your ban from the group '" + group.Name + "' was lifted",
+		Type:        "group_unbanned",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "User unbanned", http.StatusOK, "success")
+}
+
+// ReadGroupBans lists the users currently banned from the group
+func ReadGroupBans(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "ban_members"); !ok {
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	bans, err := database.ReadGroupBans(groupId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bans)
+}
+
+// checkNotBannedFromGroup refuses users with an active ban from the group
+func checkNotBannedFromGroup(w http.ResponseWriter, userId, groupId int) bool {
+	ban, err := database.GetActiveGroupBan(groupId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if ban != nil {
+		message := "You are banned from this group"
+		if ban.ExpiresAt != nil {
+			message += " until " + ban.ExpiresAt.Format("2 Jan 2006")
+		}
+		helpers.ReturnMessageJSON(w, message, http.StatusForbidden, "error")
+		return false
+	}
+	return true
+}
+
+// getManageableGroupMember reads the group and the user from the URL and makes sure the caller's role allows the action
+// on them: only members ranked below the caller can be removed or banned. When mustBeMember is false, users who
+// aren't in the group are accepted too.
+func getManageableGroupMember(w http.ResponseWriter, r *http.Request, userId int, action string, mustBeMember bool) (*structs.Group, int, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	memberId, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	role, ok := checkGroupPermission(w, userId, groupId, action)
+	if !ok || !checkGroupNotArchived(w, groupId) {
+		return nil, 0, false
+	}
+	if memberId == userId {
+		helpers.ReturnMessageJSON(w, "You can't do this to yourself, leave the group instead", http.StatusBadRequest, "error")
+		return nil, 0, false
+	}
+
+	memberRole, err := database.GetGroupMemberRole(memberId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, 0, false
+	}
+	if memberRole == "" {
+		user, err := database.GetUserById(memberId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return nil, 0, false
+		}
+		if user == nil || mustBeMember {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			return nil, 0, false
+		}
+	} else if groupRoleRanks[memberRole] >= groupRoleRanks[role] {
+		helpers.ReturnMessageJSON(w, "You can only remove members ranked below you", http.StatusForbidden, "error")
+		return nil, 0, false
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, 0, false
+	}
+	return group, memberId, true
+}
+
+// decodeMemberRemoval reads the optional reason from the request body, the body itself can be left out
+func decodeMemberRemoval(w http.ResponseWriter, r *http.Request) (structs.MemberRemoval, bool) {
+	var removal structs.MemberRemoval
+	if err := helpers.DecodeJSONBody(r, &removal); err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return removal, false
+	}
+	removal.Reason = strings.TrimSpace(removal.Reason)
+	if utf8.RuneCountInString(removal.Reason) > maxBanReasonLength {
+		http.Error(w, "The reason is too long", http.StatusBadRequest)
+		return removal, false
+	}
+	return removal, true
+}
+
+// withdrawOwnershipOffer cancels the transfer of the group to a member who is no longer in it
+func withdrawOwnershipOffer(group *structs.Group, memberId int) {
+	if group.PendingOwnerId != memberId {
+		return
+	}
+	if err := database.SetPendingGroupOwner(group.Id, 0); err != nil {
+		log.Println("Error withdrawing ownership transfer:", err)
+	}
+	if err := database.SetGroupNotificationStatus(memberId, group.Id, "group_transfer", "cancelled"); err != nil {
+		log.Println("Error updating transfer notification:", err)
+	}
+}
+
+func withReason(content, reason string) string {
+	if reason == "" {
+		return content
+	}
+	return content + ": " + reason
+}
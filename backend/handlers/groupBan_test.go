@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"social-network/structs"
+	"testing"
+)
+
+func TestGroupBanKeepsUserOut(t *testing.T) {
+	openTestDB(t)
+	ownerToken := loginTestUser(t, 1)
+	bannedToken := loginTestUser(t, 2)
+	ban := func(token string) *httptest.ResponseRecorder {
+		return doTestRequest(t, BanGroupMember, "/group/{id}/member/{userId}/ban", "POST", "/group/1/member/2/ban", token, structs.MemberRemoval{Reason: "spam"})
+	}
+	join := func() *httptest.ResponseRecorder {
+		return doTestRequest(t, SendJoinRequest, "/group/{id}/join", "POST", "/group/1/join", bannedToken, nil)
+	}
+
+	// only admins ban
+	expectStatus(t, ban(loginTestUser(t, 3)), http.StatusForbidden)
+	expectStatus(t, ban(ownerToken), http.StatusOK)
+	isMember, err := database.CheckUserIfMemberOfGroup(2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isMember {
+		t.Fatal("the banned user is still a member")
+	}
+	expectStatus(t, join(), http.StatusForbidden)
+
+	expectStatus(t, doTestRequest(t, UnbanGroupMember, "/group/{id}/member/{userId}/ban", "DELETE", "/group/1/member/2/ban", ownerToken, nil), http.StatusOK)
+	expectStatus(t, join(), http.StatusOK)
+	expectAudited(t, 1, "member_banned", "member_unbanned")
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxGroupRulesLength = 2000
+	// groupCoverDir is where group cover images are stored. It's outside the public static files, ReadGroupCover
+	// serves the covers to the users who can see the group.
+	groupCoverDir = "media/groups"
+	// legacyGroupCoverDir is where covers were stored before, they are moved to groupCoverDir when first read
+	legacyGroupCoverDir = "static/images/groups"
+)
+
+// UpdateGroup changes the details of the group, owners and admins can edit them and the other members are notified
+// when something actually changed
+func UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "edit_group"); !ok || !checkGroupNotArchived(w, groupId) {
+		return
+	}
+
+	var changes structs.GroupUpdate
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	previous := *group
+
+	if changes.Name != nil {
+		name := strings.TrimSpace(*changes.Name)
+		if name == "" {
+			http.Error(w, "Add group name and description", http.StatusBadRequest)
+			return
+		}
+		if name != group.Name {
+			exists, err := database.CheckGroupNameIfExists(name)
+			if err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			if exists {
+				helpers.ReturnMessageJSON(w, "Group name is already taken", http.StatusBadRequest, "error")
+				return
+			}
+		}
+		group.Name = name
+	}
+	if changes.Description != nil {
+		description := strings.TrimSpace(*changes.Description)
+		if description == "" {
+			http.Error(w, "Add group name and description", http.StatusBadRequest)
+			return
+		}
+		group.Description = description
+	}
+	if changes.Rules != nil {
+		rules := strings.TrimSpace(*changes.Rules)
+		if utf8.RuneCountInString(rules) > maxGroupRulesLength {
+			http.Error(w, "The rules are too long", http.StatusBadRequest)
+			return
+		}
+		group.Rules = rules
+	}
+	if changes.Visibility != nil {
+		group.Visibility, err = normalizeGroupVisibility(*changes.Visibility)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// posts already waiting stay in the queue when approval is turned off, moderators still answer them
+	if changes.PostApproval != nil {
+		group.PostApproval = *changes.PostApproval
+	}
+	if changes.CoverData != nil {
+		group.Cover = ""
+		if *changes.CoverData != "" {
+			group.Cover, err = helpers.SaveImage(*changes.CoverData, groupCoverDir)
+			if errors.Is(err, helpers.ErrInvalidImage) || errors.Is(err, helpers.ErrImageTooLarge) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if err := database.UpdateGroupDetails(*group); err != nil {
+		if group.Cover != previous.Cover {
+			removeGroupCover(group.Cover)
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if group.Cover != previous.Cover {
+		removeGroupCover(previous.Cover)
+	}
+	if changed := changedGroupSettings(previous, *group); len(changed) > 0 {
+		recordGroupAudit(groupId, userId, "settings_changed", "group", groupId, strings.Join(changed, ", "))
+		for _, memberId := range group.Members {
+			if memberId == userId {
+				continue
+			}
+			sendNotification(memberId, structs.Notification{
+				RequesterId: userId,
+				ReceiverId:  memberId,
+				GroupId:     group.Id,
+				Content:     "The details of the group '" + group.Name + "' were changed",
+				Type:        "group_updated",
+				Status:      "",
+			})
+		}
+	}
+
+	group.Role, err = database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	setGroupCoverURL(group)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(group)
+}
+
+// changedGroupSettings names the details that differ between the two versions of the group
+func changedGroupSettings(before, after structs.Group) []string {
+	changed := []string{}
+	if before.Name != after.Name {
+		changed = append(changed, "name")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.Rules != after.Rules {
+		changed = append(changed, "rules")
+	}
+	if before.Visibility != after.Visibility {
+		changed = append(changed, "visibility: "+before.Visibility+" -> "+after.Visibility)
+	}
+	if before.PostApproval != after.PostApproval {
+		changed = append(changed, "post approval: "+strconv.FormatBool(after.PostApproval))
+	}
+	if before.Cover != after.Cover {
+		changed = append(changed, "cover")
+	}
+	return changed
+}
+
+// ReadGroupCover serves the cover of a group, secret groups are hidden the same way GroupHandler hides them
+func ReadGroupCover(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := authenticateMediaRequest(w, r)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	visible, err := database.CanUserSeeGroup(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	serveGroupCover(w, r, group.Cover)
+}
+
+func serveGroupCover(w http.ResponseWriter, r *http.Request, fileName string) {
+	if fileName == "" {
+		http.Error(w, "Group has no cover", http.StatusNotFound)
+		return
+	}
+	if err := moveLegacyGroupCover(fileName); err != nil {
+		log.Println("Error moving group cover:", err)
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeFile(w, r, filepath.Join(groupCoverDir, filepath.Base(fileName)))
+}
+
+// moveLegacyGroupCover moves a cover out of the public static files if it was saved there
+func moveLegacyGroupCover(fileName string) error {
+	legacyPath := filepath.Join(legacyGroupCoverDir, filepath.Base(fileName))
+	if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(groupCoverDir, 0755); err != nil {
+		return err
+	}
+	return os.Rename(legacyPath, filepath.Join(groupCoverDir, filepath.Base(fileName)))
+}
+
+func setGroupCoverURL(group *structs.Group) {
+	if group.Cover != "" {
+		group.Cover = "/group/" + strconv.Itoa(group.Id) + "/cover"
+	}
+}
+
+func removeGroupCover(fileName string) {
+	if fileName == "" {
+		return
+	}
+	for _, dir := range []string{groupCoverDir, legacyGroupCoverDir} {
+		if err := helpers.RemoveImage(dir, fileName); err != nil {
+			log.Println("Error removing group cover:", err)
+		}
+	}
+}
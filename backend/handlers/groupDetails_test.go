@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func countGroupUpdatedNotifications(t *testing.T) int {
+	t.Helper()
+	var count int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) FROM group_notifications WHERE type = 'group_updated'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestUpdateGroupOnlyNotifiesAboutChanges(t *testing.T) {
+	openTestDB(t)
+	ownerToken := loginTestUser(t, 1)
+	update := func(changes map[string]string) {
+		expectStatus(t, doTestRequest(t, UpdateGroup, "/group/{id}", "PATCH", "/group/1", ownerToken, changes), http.StatusOK)
+	}
+
+	group, err := database.ReadGroup(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(map[string]string{})
+	update(map[string]string{"name": group.Name})
+	if count := countGroupUpdatedNotifications(t); count != 0 {
+		t.Fatalf("%d notifications about an update that changed nothing", count)
+	}
+
+	update(map[string]string{"rules": "Be kind"})
+	// users 2 and 3 are the other members
+	if count := countGroupUpdatedNotifications(t); count != 2 {
+		t.Fatalf("%d notifications, want 2", count)
+	}
+}
+
+func TestReadGroupCoverHidesSecretGroups(t *testing.T) {
+	openTestDB(t)
+	update := map[string]string{"visibility": "secret"}
+	expectStatus(t, doTestRequest(t, UpdateGroup, "/group/{id}", "PATCH", "/group/1", loginTestUser(t, 1), update), http.StatusOK)
+	readCover := func(userId int) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.HandleFunc("/group/{id}/cover", ReadGroupCover).Methods("GET")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/group/1/cover?authorization="+loginTestUser(t, userId), nil))
+		return recorder
+	}
+
+	response := readCover(createTestUser(t, "outsider@test.com"))
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "Group not found") {
+		t.Fatalf("outsider: status %d: %s", response.Code, response.Body)
+	}
+	// a member gets past the visibility check, the seeded group just has no cover
+	response = readCover(2)
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "Group has no cover") {
+		t.Fatalf("member: status %d: %s", response.Code, response.Body)
+	}
+}
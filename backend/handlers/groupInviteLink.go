@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxInviteLinkUses = 1000
+	maxInviteLinkDays = 365
+)
+
+// CreateGroupInviteLink creates a shareable link into the group. Links can expire after some days, stop working after
+// a number of uses, and either add users right away or only let them ask to join.
+func CreateGroupInviteLink(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "manage_invite_links"); !ok || !checkGroupNotArchived(w, groupId) {
+		return
+	}
+
+	var link structs.GroupInviteLink
+	if err := helpers.DecodeJSONBody(r, &link); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if link.MaxUses < 0 || link.MaxUses > maxInviteLinkUses {
+		http.Error(w, "Links can be used up to 1000 times, or 0 for no limit", http.StatusBadRequest)
+		return
+	}
+	if link.Days < 0 || link.Days > maxInviteLinkDays {
+		http.Error(w, "Links last between 1 and 365 days, or 0 for no expiry", http.StatusBadRequest)
+		return
+	}
+
+	token, err := uuid.NewV4()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	link.Token = token.String()
+	link.GroupId = groupId
+	link.CreatedBy = userId
+	link.ExpiresAt = nil
+	if link.Days > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, link.Days)
+		link.ExpiresAt = &expiresAt
+	}
+
+	linkId, err := database.InsertGroupInviteLink(link)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	saved, err := database.GetGroupInviteLinkById(linkId)
+	if err != nil || saved == nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(groupId, userId, "invite_link_created", "invite_link", saved.Id, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// ReadGroupInviteLinks lists the links of the group, revoked and expired ones included
+func ReadGroupInviteLinks(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "manage_invite_links"); !ok {
+		return
+	}
+
+	links, err := database.ReadGroupInviteLinks(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+func RevokeGroupInviteLink(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	link, ok := getManagedInviteLink(w, r, userId)
+	if !ok {
+		return
+	}
+	if link.Revoked {
+		helpers.ReturnMessageJSON(w, "The link is already revoked", http.StatusBadRequest, "error")
+		return
+	}
+
+	if err := database.RevokeGroupInviteLink(link.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(link.GroupId, userId, "invite_link_revoked", "invite_link", link.Id, "")
+
+	helpers.ReturnMessageJSON(w, "Invite link revoked", http.StatusOK, "success")
+}
+
+// ReadGroupInviteLinkUses lists who used the link, and whether they joined or asked to join
+func ReadGroupInviteLinkUses(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	link, ok := getManagedInviteLink(w, r, userId)
+	if !ok {
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	uses, err := database.ReadGroupInviteLinkUses(link.Id, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uses)
+}
+
+// PreviewInviteLink shows the group behind a link, secret groups included, so the user can decide to use it. Links that
+// create a join request also show the questions to answer.
+func PreviewInviteLink(w http.ResponseWriter, r *http.Request) {
+	_, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	link, group, ok := getUsableInviteLink(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	preview := structs.InviteLinkPreview{Group: *group, AutoApprove: link.AutoApprove}
+	if !link.AutoApprove && group.Visibility != "public" {
+		preview.Questions, err = database.ReadGroupQuestions(group.Id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if preview.Group.Cover != "" {
+		// the user may not be able to see the group yet, the cover is read through the link
+		preview.Group.Cover = "/invite/" + link.Token + "/cover"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// ReadInviteLinkCover serves the cover of the group behind a link, holding the link is enough to see it
+func ReadInviteLinkCover(w http.ResponseWriter, r *http.Request) {
+	_, isAuthenticated := authenticateMediaRequest(w, r)
+	if !isAuthenticated {
+		return
+	}
+
+	_, group, ok := getUsableInviteLink(w, r)
+	if !ok {
+		return
+	}
+	serveGroupCover(w, r, group.Cover)
+}
+
+// JoinWithInviteLink uses the link, the user joins the group right away or asks to join it depending on the link
+func JoinWithInviteLink(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	link, group, ok := getUsableInviteLink(w, r)
+	if !ok {
+		return
+	}
+	if !checkGroupNotArchived(w, group.Id) {
+		return
+	}
+
+	isMember, err := database.CheckUserIfMemberOfGroup(userId, group.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if isMember {
+		http.Error(w, "You're already a member of this group", http.StatusBadRequest)
+		return
+	}
+	if !checkNotBannedFromGroup(w, userId, group.Id) {
+		return
+	}
+
+	// anyone can join a public group, so links into one never need approval
+	status := "requested"
+	var answers []structs.JoinRequestAnswer
+	if link.AutoApprove || group.Visibility == "public" {
+		status = "joined"
+	} else if !checkCanRequestToJoin(w, userId, group.Id) {
+		return
+	} else if answers, ok = readJoinRequestAnswers(w, r, group.Id); !ok {
+		return
+	}
+
+	userName, err := userDisplayName(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// the use is only counted together with the join or the request, a failed one doesn't use up the link
+	var used bool
+	if status == "joined" {
+		used, err = database.JoinGroupWithInviteLink(link.Id, userId, group.Id)
+	} else {
+		used, err = database.RequestToJoinWithInviteLink(link.Id, userId, group.Id, answers)
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !used {
+		http.Error(w, "This invite link is invalid or has expired", http.StatusNotFound)
+		return
+	}
+
+	if status == "joined" {
+		announceGroupJoin(w, userId, userName, group)
+	} else {
+		announceJoinRequest(w, userId, userName, group, answers)
+	}
+}
+
+// getManagedInviteLink reads the group and the link from the URL and makes sure the user can manage the group's links
+func getManagedInviteLink(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupInviteLink, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+	linkId, err := strconv.Atoi(vars["linkId"])
+	if err != nil {
+		http.Error(w, "Invalid link ID", http.StatusBadRequest)
+		return nil, false
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "manage_invite_links"); !ok {
+		return nil, false
+	}
+
+	link, err := database.GetGroupInviteLinkById(linkId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if link == nil || link.GroupId != groupId {
+		http.Error(w, "Invite link not found", http.StatusNotFound)
+		return nil, false
+	}
+	return link, true
+}
+
+// getUsableInviteLink reads the link from its token in the URL, links that were revoked, expired or used up are
+// reported as not found
+func getUsableInviteLink(w http.ResponseWriter, r *http.Request) (*structs.GroupInviteLink, *structs.Group, bool) {
+	link, err := database.GetGroupInviteLinkByToken(mux.Vars(r)["token"])
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if link == nil || link.Revoked || (link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now())) ||
+		(link.MaxUses > 0 && link.Uses >= link.MaxUses) {
+		http.Error(w, "This invite link is invalid or has expired", http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	group, err := database.ReadGroup(link.GroupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return link, group, true
+}
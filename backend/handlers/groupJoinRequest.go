@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// JoinRequestCooldown is how long a user whose request was declined waits before asking again,
+// main overrides it from JOIN_REQUEST_COOLDOWN_DAYS
+var JoinRequestCooldown = 7 * 24 * time.Hour
+
+// JoinRequestLifetime is how long join requests and invitations wait for an answer before they expire,
+// main overrides it from JOIN_REQUEST_EXPIRY_DAYS
+var JoinRequestLifetime = 30 * 24 * time.Hour
+
+// WithdrawJoinRequest lets the user take back their request to join the group before it is answered
+func WithdrawJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	withdrawn, err := database.WithdrawJoinRequest(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !withdrawn {
+		helpers.ReturnMessageJSON(w, "You have no pending request to join this group", http.StatusBadRequest, "error")
+		return
+	}
+	if err := database.SetInviteLinkUseStatus(groupId, userId, "withdrawn"); err != nil {
+		log.Println("Error updating invite link use:", err)
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	userName, err := userDisplayName(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	approverIds, err := database.GetGroupMemberIdsByRole(groupId, groupRolesAllowed("approve_join_requests")...)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for _, approverId := range approverIds {
+		sendNotification(approverId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  approverId,
+			GroupId:     groupId,
+			Content:     userName + " withdrew their request to join your group '" + group.Name + "'",
+			Type:        "join_request_withdrawn",
+			Status:      "",
+		})
+	}
+
+	helpers.ReturnMessageJSON(w, "Join request withdrawn", http.StatusOK, "success")
+}
+
+// RunJoinRequestExpiry expires stale join requests and invitations on every tick, it's started from main in its own goroutine
+func RunJoinRequestExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		expireJoinRequests(time.Now())
+	}
+}
+
+func expireJoinRequests(now time.Time) {
+	expired, err := database.ExpireJoinRequests(now.Add(-JoinRequestLifetime))
+	if err != nil {
+		log.Println("Error expiring join requests:", err)
+		return
+	}
+
+	groupNames := make(map[int]string)
+	for _, request := range expired {
+		if err := database.SetInviteLinkUseStatus(request.GroupId, request.UserId, "expired"); err != nil {
+			log.Println("Error updating invite link use:", err)
+		}
+
+		if _, ok := groupNames[request.GroupId]; !ok {
+			group, err := database.ReadGroup(request.GroupId)
+			if err != nil {
+				log.Println("Error reading group of expired join request:", err)
+				continue
+			}
+			groupNames[request.GroupId] = group.Name
+		}
+
+		content := "Your request to join the group '" + groupNames[request.GroupId] + "' expired"
+		notificationType := "join_request_expired"
+		if request.Kind == "invitation" {
+			content = "Your invitation to the group '" + groupNames[request.GroupId] + "' expired"
+			notificationType = "invitation_expired"
+		}
+		sendNotification(request.UserId, structs.Notification{
+			ReceiverId: request.UserId,
+			GroupId:    request.GroupId,
+			Content:    content,
+			Type:       notificationType,
+			Status:     "",
+		})
+	}
+}
+
+// checkPendingJoinRequest makes sure the user has a request to join the group waiting for an answer
+func checkPendingJoinRequest(w http.ResponseWriter, userId, groupId int) bool {
+	request, err := database.GetJoinRequest(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if request == nil || request.Kind != "request" || request.Status != "pending" {
+		helpers.ReturnMessageJSON(w, "There is no pending join request from this user", http.StatusBadRequest, "error")
+		return false
+	}
+	return true
+}
+
+// joinRequestReapplyTime is when the user can ask to join again after their request was declined
+func joinRequestReapplyTime(request *structs.GroupJoinRequest) time.Time {
+	answeredAt := request.CreatedAt
+	if request.RespondedAt != nil {
+		answeredAt = *request.RespondedAt
+	}
+	return answeredAt.Add(JoinRequestCooldown)
+}
+
+func userDisplayName(userId int) (string, error) {
+	userInfo, err := database.GetUserMainInfo(userId)
+	if err != nil {
+		return "", err
+	}
+	if userInfo.Nickname == "" {
+		return userInfo.FirstName + " " + userInfo.LastName, nil
+	}
+	return userInfo.Nickname, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"social-network/structs"
+	"testing"
+)
+
+func TestJoinRequestLifecycle(t *testing.T) {
+	openTestDB(t)
+	ownerToken := loginTestUser(t, 1)
+	applicantId := createTestUser(t, "applicant@test.com")
+	applicantToken := loginTestUser(t, applicantId)
+
+	join := func(token string) *httptest.ResponseRecorder {
+		return doTestRequest(t, SendJoinRequest, "/group/{id}/join", "POST", "/group/1/join", token, nil)
+	}
+	withdraw := func() *httptest.ResponseRecorder {
+		return doTestRequest(t, WithdrawJoinRequest, "/group/{id}/join/withdraw", "POST", "/group/1/join/withdraw", applicantToken, nil)
+	}
+	answer := func(handler http.HandlerFunc, action string, requesterId int) *httptest.ResponseRecorder {
+		request := structs.JoinRequest{RequesterId: requesterId, GroupId: 1}
+		return doTestRequest(t, handler, "/group/{id}/join/"+action, "POST", "/group/1/join/"+action, ownerToken, request)
+	}
+
+	expectStatus(t, join(applicantToken), http.StatusOK)
+	// one request at a time
+	expectStatus(t, join(applicantToken), http.StatusBadRequest)
+	expectStatus(t, withdraw(), http.StatusOK)
+	expectStatus(t, withdraw(), http.StatusBadRequest)
+	expectStatus(t, join(applicantToken), http.StatusOK)
+	expectStatus(t, answer(DeclineJoinRequest, "decline", applicantId), http.StatusOK)
+	// a request is only answered once, and a declined user has to wait before asking again
+	expectStatus(t, answer(AcceptJoinRequest, "accept", applicantId), http.StatusBadRequest)
+	expectStatus(t, join(applicantToken), http.StatusBadRequest)
+	status, err := database.GetJoinRequestStatus(applicantId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "declined" {
+		t.Fatalf("status = %q, want declined", status)
+	}
+
+	acceptedId := createTestUser(t, "accepted@test.com")
+	expectStatus(t, join(loginTestUser(t, acceptedId)), http.StatusOK)
+	expectStatus(t, answer(AcceptJoinRequest, "accept", acceptedId), http.StatusOK)
+	isMember, err := database.CheckUserIfMemberOfGroup(acceptedId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isMember {
+		t.Fatal("the accepted user isn't a member")
+	}
+	expectAudited(t, 1, "member_joined")
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// checkGroupNotArchived refuses changes to an archived group, it's read-only until the owner unarchives it
+func checkGroupNotArchived(w http.ResponseWriter, groupId int) bool {
+	archived, err := database.IsGroupArchived(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
+		return false
+	}
+	return true
+}
+
+// TransferGroupOwnership offers the group to another member, they become the owner once they accept
+func TransferGroupOwnership(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOwnedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+
+	var transfer structs.OwnershipTransfer
+	if err := helpers.DecodeJSONBody(r, &transfer); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if transfer.UserId == userId {
+		helpers.ReturnMessageJSON(w, "You already own this group", http.StatusBadRequest, "error")
+		return
+	}
+	isMember, err := database.CheckUserIfMemberOfGroup(transfer.UserId, group.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		helpers.ReturnMessageJSON(w, "The group can only be transferred to a member", http.StatusBadRequest, "error")
+		return
+	}
+
+	if group.PendingOwnerId != 0 {
+		if err := database.SetGroupNotificationStatus(group.PendingOwnerId, group.Id, "group_transfer", "cancelled"); err != nil {
+			log.Println("Error updating transfer notification:", err)
+		}
+	}
+	if err := database.SetPendingGroupOwner(group.Id, transfer.UserId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	sendNotification(transfer.UserId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  transfer.UserId,
+		GroupId:     group.Id,
+		Content:     "You were offered the ownership of the group '" + group.Name + "'",
+		Type:        "group_transfer",
+		Status:      "pending",
+	})
+
+	helpers.ReturnMessageJSON(w, "Ownership transfer offered", http.StatusOK, "success")
+}
+
+// CancelGroupOwnershipTransfer withdraws the owner's offer before it is accepted
+func CancelGroupOwnershipTransfer(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOwnedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+	if group.PendingOwnerId == 0 {
+		helpers.ReturnMessageJSON(w, "There is no pending ownership transfer", http.StatusBadRequest, "error")
+		return
+	}
+
+	if err := database.SetPendingGroupOwner(group.Id, 0); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := database.SetGroupNotificationStatus(group.PendingOwnerId, group.Id, "group_transfer", "cancelled"); err != nil {
+		log.Println("Error updating transfer notification:", err)
+	}
+
+	helpers.ReturnMessageJSON(w, "Ownership transfer cancelled", http.StatusOK, "success")
+}
+
+// AcceptGroupOwnership makes the user the owner of the group they were offered, the previous owner stays on as an admin
+func AcceptGroupOwnership(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOfferedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+
+	isMember, err := database.CheckUserIfMemberOfGroup(userId, group.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !isMember {
+		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+		return
+	}
+
+	transferred, err := database.TransferGroupOwnership(group.Id, group.CreatorId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !transferred {
+		helpers.ReturnMessageJSON(w, "The ownership transfer was withdrawn", http.StatusBadRequest, "error")
+		return
+	}
+	if err := database.SetGroupNotificationStatus(userId, group.Id, "group_transfer", "accepted"); err != nil {
+		log.Println("Error updating transfer notification:", err)
+	}
+	recordGroupAudit(group.Id, userId, "ownership_transferred", "user", userId, "from "+strconv.Itoa(group.CreatorId))
+
+	sendNotification(group.CreatorId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  group.CreatorId,
+		GroupId:     group.Id,
+		Content:     "The ownership of the group '" + group.Name + "' was accepted, you are now an admin",
+		Type:        "group_transfer",
+		Status:      "accepted",
+	})
+
+	helpers.ReturnMessageJSON(w, "You are now the owner of the group", http.StatusOK, "success")
+}
+
+func DeclineGroupOwnership(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOfferedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.SetPendingGroupOwner(group.Id, 0); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := database.SetGroupNotificationStatus(userId, group.Id, "group_transfer", "declined"); err != nil {
+		log.Println("Error updating transfer notification:", err)
+	}
+
+	sendNotification(group.CreatorId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  group.CreatorId,
+		GroupId:     group.Id,
+		Content:     "The ownership of the group '" + group.Name + "' was declined",
+		Type:        "group_transfer",
+		Status:      "declined",
+	})
+
+	helpers.ReturnMessageJSON(w, "Ownership transfer declined", http.StatusOK, "success")
+}
+
+// ArchiveGroup makes the group read-only, members keep access to everything in it
+func ArchiveGroup(w http.ResponseWriter, r *http.Request) {
+	setGroupArchived(w, r, true)
+}
+
+func UnarchiveGroup(w http.ResponseWriter, r *http.Request) {
+	setGroupArchived(w, r, false)
+}
+
+func setGroupArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOwnedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+	if group.Archived == archived {
+		helpers.ReturnMessageJSON(w, "Nothing to change", http.StatusBadRequest, "error")
+		return
+	}
+
+	if err := database.SetGroupArchived(group.Id, archived); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	content, message, action := "The group '"+group.Name+"' was archived and is now read-only", "Group archived", "group_archived"
+	if !archived {
+		content, message, action = "The group '"+group.Name+"' was reopened", "Group unarchived", "group_unarchived"
+	}
+	recordGroupAudit(group.Id, userId, action, "group", group.Id, "")
+	for _, memberId := range group.Members {
+		if memberId == userId {
+			continue
+		}
+		sendNotification(memberId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  memberId,
+			GroupId:     group.Id,
+			Content:     content,
+			Type:        "group_archived",
+			Status:      "",
+		})
+	}
+
+	helpers.ReturnMessageJSON(w, message, http.StatusOK, "success")
+}
+
+// DeleteGroup deletes the group with all its posts, comments, events, requests, notifications and chat messages
+func DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	group, ok := getOwnedGroup(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteGroup(group.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	removeGroupCover(group.Cover)
+
+	// the group's notifications are gone with it, so this one isn't linked to the group
+	for _, memberId := range group.Members {
+		if memberId == userId {
+			continue
+		}
+		sendNotification(memberId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  memberId,
+			Content:     "The group '" + group.Name + "' was deleted by its owner",
+			Type:        "group_deleted",
+			Status:      "",
+		})
+	}
+
+	helpers.ReturnMessageJSON(w, "Group deleted", http.StatusOK, "success")
+}
+
+// getOwnedGroup reads the group from the URL and makes sure the user owns it
+func getOwnedGroup(w http.ResponseWriter, r *http.Request, userId int) (*structs.Group, bool) {
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	role, err := database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if role != "owner" {
+		helpers.ReturnMessageJSON(w, "You are not the owner of this group", http.StatusForbidden, "error")
+		return nil, false
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return group, true
+}
+
+// getOfferedGroup reads the group from the URL and makes sure its owner offered it to the user
+func getOfferedGroup(w http.ResponseWriter, r *http.Request, userId int) (*structs.Group, bool) {
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Group does not exist", http.StatusBadRequest)
+		return nil, false
+	}
+	if group.PendingOwnerId != userId {
+		helpers.ReturnMessageJSON(w, "You weren't offered this group", http.StatusBadRequest, "error")
+		return nil, false
+	}
+	return group, true
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const maxPostRejectionReasonLength = 500
+
+// ReadPendingGroupPosts lists the posts of the group waiting for a moderator's approval
+func ReadPendingGroupPosts(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "approve_posts"); !ok {
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	posts, err := database.ReadPendingGroupPosts(groupId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderGroupPostsContent(posts)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(posts)
+}
+
+func ApproveGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getPendingGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	approved, err := database.ReviewGroupPost(post.Id, "published")
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !approved {
+		helpers.ReturnMessageJSON(w, "The post has already been reviewed", http.StatusBadRequest, "error")
+		return
+	}
+	post.Status = "published"
+	recordGroupAudit(post.GroupId, userId, "post_approved", "group_post", post.Id, "author "+strconv.Itoa(post.UserId))
+	if err := announceGroupPost(*post); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	group, err := database.ReadGroup(post.GroupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	sendNotification(post.UserId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  post.UserId,
+		GroupId:     post.GroupId,
+		Content:     "Your post '" + post.Title + "' was approved in the group '" + group.Name + "'",
+		Type:        "group_post_approved",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "Post approved", http.StatusOK, "success")
+}
+
+// RejectGroupPost keeps the pending post out of the group, the author is told why
+func RejectGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getPendingGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+	var rejection structs.PostRejection
+	if err := helpers.DecodeJSONBody(r, &rejection); err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	rejection.Reason = strings.TrimSpace(rejection.Reason)
+	if utf8.RuneCountInString(rejection.Reason) > maxPostRejectionReasonLength {
+		http.Error(w, "The reason is too long", http.StatusBadRequest)
+		return
+	}
+
+	rejected, err := database.ReviewGroupPost(post.Id, "rejected")
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !rejected {
+		helpers.ReturnMessageJSON(w, "The post has already been reviewed", http.StatusBadRequest, "error")
+		return
+	}
+	recordGroupAudit(post.GroupId, userId, "post_rejected", "group_post", post.Id, withReason("author "+strconv.Itoa(post.UserId), rejection.Reason))
+
+	group, err := database.ReadGroup(post.GroupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	sendNotification(post.UserId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  post.UserId,
+		GroupId:     post.GroupId,
+		Content:     withReason("Your post '"+post.Title+"' was rejected in the group '"+group.Name+"'", rejection.Reason),
+		Type:        "group_post_rejected",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "Post rejected", http.StatusOK, "success")
+}
+
+// groupPostPublishStatus is the status a post of the user goes out with: "pending" when the group approves posts
+// first and the user can't approve them, "published" otherwise
+func groupPostPublishStatus(userId, groupId int) (string, error) {
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		return "", err
+	}
+	if !group.PostApproval {
+		return "published", nil
+	}
+	role, err := database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		return "", err
+	}
+	if groupRoleAllows(role, "approve_posts") {
+		return "published", nil
+	}
+	return "pending", nil
+}
+
+// notifyPostAwaitingApproval tells everyone who can approve posts in the group that one is waiting
+func notifyPostAwaitingApproval(post structs.GroupPost) {
+	group, err := database.ReadGroup(post.GroupId)
+	if err != nil {
+		log.Println("Error reading group:", err)
+		return
+	}
+	approverIds, err := database.GetGroupMemberIdsByRole(post.GroupId, groupRolesAllowed("approve_posts")...)
+	if err != nil {
+		log.Println("Error getting group approvers:", err)
+		return
+	}
+	for _, approverId := range approverIds {
+		sendNotification(approverId, structs.Notification{
+			RequesterId: post.UserId,
+			ReceiverId:  approverId,
+			GroupId:     post.GroupId,
+			Content:     "A new post '" + post.Title + "' is waiting for approval in the group '" + group.Name + "'",
+			Type:        "group_post_pending",
+			Status:      "",
+		})
+	}
+}
+
+// getPendingGroupPost reads the group and the post from the URL and makes sure the user can approve posts there
+func getPendingGroupPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupPost, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "approve_posts"); !ok || !checkGroupNotArchived(w, groupId) {
+		return nil, false
+	}
+
+	post, err := database.GetGroupPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil || post.GroupId != groupId || post.Status != "pending" {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	return post, true
+}
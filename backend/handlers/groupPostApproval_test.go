@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"social-network/structs"
+	"strconv"
+	"testing"
+)
+
+func TestGroupPostApprovalQueue(t *testing.T) {
+	openTestDB(t)
+	ownerToken := loginTestUser(t, 1)
+	authorToken := loginTestUser(t, 2)
+
+	expectStatus(t, doTestRequest(t, UpdateGroup, "/group/{id}", "PATCH", "/group/1", ownerToken, map[string]bool{"postApproval": true}), http.StatusOK)
+
+	createPost := func(title string) int {
+		response := doTestRequest(t, CreateGroupPost, "/group/{id}/post/create", "POST", "/group/1/post/create", authorToken, structs.GroupPost{Title: title, Content: "content"})
+		expectStatus(t, response, http.StatusOK)
+		var post structs.GroupPost
+		if err := json.NewDecoder(response.Body).Decode(&post); err != nil {
+			t.Fatal(err)
+		}
+		if post.Status != "pending" {
+			t.Fatalf("new post status = %q, want pending", post.Status)
+		}
+		return post.Id
+	}
+	review := func(handler http.HandlerFunc, action string, postId int, token string) *httptest.ResponseRecorder {
+		url := "/group/1/post/" + strconv.Itoa(postId) + "/" + action
+		return doTestRequest(t, handler, "/group/{groupId}/post/{postId}/"+action, "POST", url, token, structs.PostRejection{Reason: "off topic"})
+	}
+	approvedId := createPost("Approved")
+	rejectedId := createPost("Rejected")
+
+	// members can't approve posts
+	expectStatus(t, review(ApproveGroupPost, "approve", approvedId, loginTestUser(t, 3)), http.StatusForbidden)
+	expectStatus(t, review(ApproveGroupPost, "approve", approvedId, ownerToken), http.StatusOK)
+	// a reviewed post leaves the queue
+	expectStatus(t, review(ApproveGroupPost, "approve", approvedId, ownerToken), http.StatusNotFound)
+	expectStatus(t, review(RejectGroupPost, "reject", approvedId, ownerToken), http.StatusNotFound)
+	expectStatus(t, review(RejectGroupPost, "reject", rejectedId, ownerToken), http.StatusOK)
+	expectStatus(t, review(ApproveGroupPost, "approve", rejectedId, ownerToken), http.StatusNotFound)
+
+	for postId, want := range map[int]string{approvedId: "published", rejectedId: "rejected"} {
+		post, err := database.GetGroupPostById(postId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Status != want {
+			t.Errorf("post %d status = %q, want %q", postId, post.Status, want)
+		}
+	}
+	expectAudited(t, 1, "settings_changed", "post_approved", "post_rejected")
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxGroupQuestions          = 5
+	maxGroupQuestionLength     = 300
+	maxJoinRequestAnswerLength = 1000
+)
+
+// ReadGroupQuestions lists the questions users answer when they ask to join the group
+func ReadGroupQuestions(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	visible, err := database.CanUserSeeGroup(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	questions, err := database.ReadGroupQuestions(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(questions)
+}
+
+// UpdateGroupQuestions replaces the membership questions of the group. Requests already sent keep the questions
+// they were answered with.
+func UpdateGroupQuestions(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "edit_group"); !ok || !checkGroupNotArchived(w, groupId) {
+		return
+	}
+
+	var update structs.GroupQuestions
+	if err := helpers.DecodeJSONBody(r, &update); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if len(update.Questions) > maxGroupQuestions {
+		http.Error(w, "A group can ask up to 5 questions", http.StatusBadRequest)
+		return
+	}
+	questions := make([]string, 0, len(update.Questions))
+	for _, question := range update.Questions {
+		question = strings.TrimSpace(question)
+		if question == "" {
+			http.Error(w, "Questions can't be empty", http.StatusBadRequest)
+			return
+		}
+		if utf8.RuneCountInString(question) > maxGroupQuestionLength {
+			http.Error(w, "A question is too long", http.StatusBadRequest)
+			return
+		}
+		questions = append(questions, question)
+	}
+
+	if err := database.ReplaceGroupQuestions(groupId, questions); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(groupId, userId, "questions_changed", "group", groupId, strconv.Itoa(len(questions))+" questions")
+	saved, err := database.ReadGroupQuestions(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// ReadPendingJoinRequests lists the requests to join the group waiting for an answer, together with the answers
+// to the membership questions
+func ReadPendingJoinRequests(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := checkGroupPermission(w, userId, groupId, "approve_join_requests"); !ok {
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	requests, err := database.ReadPendingJoinRequests(groupId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// readJoinRequestAnswers reads the answers to the group's membership questions from the request body, every question
+// must be answered. The body can be left out when the group asks none.
+func readJoinRequestAnswers(w http.ResponseWriter, r *http.Request, groupId int) ([]structs.JoinRequestAnswer, bool) {
+	questions, err := database.ReadGroupQuestions(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	var body structs.JoinRequestAnswers
+	if err := helpers.DecodeJSONBody(r, &body); err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return nil, false
+	}
+	if len(body.Answers) != len(questions) {
+		helpers.ReturnMessageJSON(w, "Answer all of the group's questions to ask to join", http.StatusBadRequest, "error")
+		return nil, false
+	}
+
+	answers := make([]structs.JoinRequestAnswer, 0, len(questions))
+	for i, question := range questions {
+		answer := strings.TrimSpace(body.Answers[i])
+		if answer == "" {
+			helpers.ReturnMessageJSON(w, "Answer all of the group's questions to ask to join", http.StatusBadRequest, "error")
+			return nil, false
+		}
+		if utf8.RuneCountInString(answer) > maxJoinRequestAnswerLength {
+			http.Error(w, "An answer is too long", http.StatusBadRequest)
+			return nil, false
+		}
+		answers = append(answers, structs.JoinRequestAnswer{Question: question.Question, Answer: answer})
+	}
+	return answers, true
+}
+
+// withAnswers adds the applicant's answers to the join request notification
+func withAnswers(content string, answers []structs.JoinRequestAnswer) string {
+	for _, answer := range answers {
+		content += "\n\n" + answer.Question + "\n" + answer.Answer
+	}
+	return content
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// groupRoleRanks orders the roles of group members, every role can do what the roles below it can
+var groupRoleRanks = map[string]int{
+	"member":    0,
+	"moderator": 1,
+	"admin":     2,
+	"owner":     3,
+}
+
+// groupPermissions is the lowest role allowed to take each restricted action in a group
+var groupPermissions = map[string]string{
+	"approve_join_requests": "moderator",
+	"invite_members":        "moderator",
+	"delete_content":        "moderator",
+	"create_events":         "moderator",
+	"remove_members":        "moderator",
+	"approve_posts":         "moderator",
+	"edit_group":            "admin",
+	"manage_roles":          "admin",
+	"ban_members":           "admin",
+	"manage_invite_links":   "admin",
+	"view_audit_log":        "admin",
+}
+
+// groupRoleAllows reports whether the role is allowed to take the action, non-members ("") never are
+func groupRoleAllows(role, action string) bool {
+	minimumRole, ok := groupPermissions[action]
+	if role == "" || !ok {
+		return false
+	}
+	return groupRoleRanks[role] >= groupRoleRanks[minimumRole]
+}
+
+// groupRolesAllowed returns every role that is allowed to take the action
+func groupRolesAllowed(action string) []string {
+	roles := []string{}
+	for role := range groupRoleRanks {
+		if groupRoleAllows(role, action) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// checkGroupPermission makes sure the user's role in the group allows the action and returns the role
+func checkGroupPermission(w http.ResponseWriter, userId, groupId int, action string) (string, bool) {
+	role, err := database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return "", false
+	}
+	if !groupRoleAllows(role, action) {
+		helpers.ReturnMessageJSON(w, "You don't have permission to do this in this group", http.StatusForbidden, "error")
+		return "", false
+	}
+	return role, true
+}
+
+// ReadGroupMembers lists the members of the group with their roles
+func ReadGroupMembers(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	groupId, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+
+	if !checkGroupReadable(w, userId, groupId) {
+		return
+	}
+
+	members, err := database.ReadGroupMembers(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// ChangeGroupMemberRole promotes or demotes a member. Members can only be given a role below the caller's own,
+// and only members ranked below the caller can be changed, so admins manage moderators and the owner manages admins.
+func ChangeGroupMemberRole(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	memberId, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	role, ok := checkGroupPermission(w, userId, groupId, "manage_roles")
+	if !ok || !checkGroupNotArchived(w, groupId) {
+		return
+	}
+
+	var change structs.RoleChange
+	if err := helpers.DecodeJSONBody(r, &change); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if _, ok := groupRoleRanks[change.Role]; !ok || change.Role == "owner" {
+		http.Error(w, "Invalid role, use admin, moderator or member", http.StatusBadRequest)
+		return
+	}
+
+	if memberId == userId {
+		helpers.ReturnMessageJSON(w, "You can't change your own role", http.StatusBadRequest, "error")
+		return
+	}
+	memberRole, err := database.GetGroupMemberRole(memberId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if memberRole == "" {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	}
+	if groupRoleRanks[memberRole] >= groupRoleRanks[role] || groupRoleRanks[change.Role] >= groupRoleRanks[role] {
+		helpers.ReturnMessageJSON(w, "You can only manage roles below your own", http.StatusForbidden, "error")
+		return
+	}
+	if memberRole == change.Role {
+		helpers.ReturnMessageJSON(w, "The member already has this role", http.StatusBadRequest, "error")
+		return
+	}
+
+	if err := database.SetGroupMemberRole(memberId, groupId, change.Role); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(groupId, userId, "role_changed", "user", memberId, memberRole+" -> "+change.Role)
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	sendNotification(memberId, structs.Notification{
+		RequesterId: userId,
+		ReceiverId:  memberId,
+		GroupId:     groupId,
+		Content:     "Your role in the group '" + group.Name + "' is now " + change.Role,
+		Type:        "group_role",
+		Status:      "",
+	})
+
+	helpers.ReturnMessageJSON(w, "Role updated", http.StatusOK, "success")
+}
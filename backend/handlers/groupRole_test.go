@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"testing"
+)
+
+func TestCheckGroupPermission(t *testing.T) {
+	openTestDB(t)
+	outsiderId := createTestUser(t, "outsider@test.com")
+	if err := database.SetGroupMemberRole(2, 1, "moderator"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetGroupMemberRole(3, 1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		userId  int
+		action  string
+		allowed bool
+	}{
+		{1, "manage_roles", true},
+		{1, "view_audit_log", true},
+		{1, "unknown_action", false},
+		{3, "ban_members", true},
+		{3, "approve_posts", true},
+		{2, "approve_posts", true},
+		{2, "remove_members", true},
+		{2, "create_events", true},
+		{2, "ban_members", false},
+		{2, "manage_roles", false},
+		{2, "edit_group", false},
+		{outsiderId, "approve_posts", false},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		_, allowed := checkGroupPermission(recorder, test.userId, 1, test.action)
+		if allowed != test.allowed {
+			t.Errorf("user %d %s: allowed = %v, want %v", test.userId, test.action, allowed, test.allowed)
+		}
+		if !allowed && recorder.Code != http.StatusForbidden {
+			t.Errorf("user %d %s: status %d, want %d", test.userId, test.action, recorder.Code, http.StatusForbidden)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"strings"
+)
+
+// groupVisibilities are the visibility levels of a group:
+// public groups can be read and joined by anyone, private ones are listed but only members see their content
+// and joining needs approval, secret ones are hidden from everyone but their members and can only be joined by invitation
+var groupVisibilities = map[string]bool{
+	"public":  true,
+	"private": true,
+	"secret":  true,
+}
+
+// normalizeGroupVisibility checks the visibility of a group, groups are private unless told otherwise
+func normalizeGroupVisibility(visibility string) (string, error) {
+	visibility = strings.ToLower(strings.TrimSpace(visibility))
+	if visibility == "" {
+		return "private", nil
+	}
+	if !groupVisibilities[visibility] {
+		return "", errors.New("Visibility must be public, private or secret")
+	}
+	return visibility, nil
+}
+
+// checkGroupReadable makes sure the user can read the content of the group, members always can and everyone else
+// only when the group is public
+func checkGroupReadable(w http.ResponseWriter, userId, groupId int) bool {
+	readable, err := database.CanUserReadGroup(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+	if !readable {
+		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+		return false
+	}
+	return true
+}
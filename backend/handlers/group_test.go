@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/structs"
+	"testing"
+)
+
+func readTestGroup(t *testing.T, userId int) structs.Group {
+	t.Helper()
+	response := doTestRequest(t, GroupHandler, "/group/{id}/get", "GET", "/group/1/get", loginTestUser(t, userId), nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", response.Code, response.Body)
+	}
+	var group structs.Group
+	if err := json.NewDecoder(response.Body).Decode(&group); err != nil {
+		t.Fatal(err)
+	}
+	return group
+}
+
+func TestGroupHandlerHidesMembersFromOutsiders(t *testing.T) {
+	openTestDB(t)
+	outsiderId := createTestUser(t, "outsider@test.com")
+
+	if group := readTestGroup(t, 2); len(group.Members) != 3 {
+		t.Fatalf("member sees %d members, want 3", len(group.Members))
+	}
+	group := readTestGroup(t, outsiderId)
+	if group.Visibility != "private" {
+		t.Fatalf("visibility = %q, want the seeded private group", group.Visibility)
+	}
+	if len(group.Members) != 0 {
+		t.Fatalf("outsider sees the members of a private group: %v", group.Members)
+	}
+}
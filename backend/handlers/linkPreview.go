@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"log"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"sync"
+	"time"
+)
+
+// linkPreviewFetcher is a variable so tests can replace it with a fetcher pointed at a local server
+var linkPreviewFetcher helpers.LinkPreviewFetcher = helpers.NewLinkPreviewFetcher()
+
+const linkPreviewMaxAge = 24 * time.Hour
+
+const (
+	linkPreviewWorkers   = 4
+	linkPreviewQueueSize = 100
+)
+
+// linkPreviewFetches fetches pages on a fixed number of workers. A page is only fetched once at a time, everyone
+// publishing it while it's queued or being fetched gets the same preview.
+type linkPreviewFetches struct {
+	start   sync.Once
+	queue   chan string
+	mutex   sync.Mutex
+	waiting map[string][]func(*structs.LinkPreview)
+}
+
+var linkPreviewQueue = &linkPreviewFetches{
+	queue:   make(chan string, linkPreviewQueueSize),
+	waiting: make(map[string][]func(*structs.LinkPreview)),
+}
+
+// add queues the page unless it's already on its way, when the queue is full the page is skipped and fetched the
+// next time it's published
+func (f *linkPreviewFetches) add(url string, onFetched func(*structs.LinkPreview)) {
+	f.start.Do(func() {
+		for i := 0; i < linkPreviewWorkers; i++ {
+			go f.work()
+		}
+	})
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if callbacks, ok := f.waiting[url]; ok {
+		f.waiting[url] = append(callbacks, onFetched)
+		return
+	}
+	select {
+	case f.queue <- url:
+		f.waiting[url] = []func(*structs.LinkPreview){onFetched}
+	default:
+		log.Println("Link preview queue is full, skipping", url)
+	}
+}
+
+func (f *linkPreviewFetches) work() {
+	for url := range f.queue {
+		preview := fetchLinkPreview(url)
+
+		f.mutex.Lock()
+		callbacks := f.waiting[url]
+		delete(f.waiting, url)
+		f.mutex.Unlock()
+
+		if preview == nil {
+			continue
+		}
+		for _, onFetched := range callbacks {
+			if onFetched != nil {
+				onFetched(preview)
+			}
+		}
+	}
+}
+
+// loadLinkPreview returns the cached preview of the first link in the content. When it's missing or old the page is
+// queued to be fetched in the background so publishing never waits on another site, onFetched gets the preview
+// once it's there.
+// Reads only use the cache through cachedLinkPreview.
+func loadLinkPreview(content string, onFetched func(*structs.LinkPreview)) *structs.LinkPreview {
+	url := helpers.ExtractFirstURL(content)
+	if url == "" {
+		return nil
+	}
+
+	preview, fetchedAt, err := database.GetLinkPreview(url)
+	if err != nil {
+		log.Println("Error reading cached link preview:", err)
+		return nil
+	}
+	if preview == nil || time.Since(fetchedAt) >= linkPreviewMaxAge {
+		linkPreviewQueue.add(url, onFetched)
+	}
+	return usableLinkPreview(preview)
+}
+
+// fetchLinkPreview fetches the page and caches its preview
+func fetchLinkPreview(url string) *structs.LinkPreview {
+	preview, err := linkPreviewFetcher.Fetch(url)
+	if err != nil {
+		log.Println("Error fetching link preview:", err)
+		// the failure is cached as an empty preview so the page isn't fetched again on every post
+		preview = &structs.LinkPreview{Url: url}
+	}
+	if err := database.SaveLinkPreview(*preview); err != nil {
+		log.Println("Error saving link preview:", err)
+	}
+	return usableLinkPreview(preview)
+}
+
+func cachedLinkPreview(content string) *structs.LinkPreview {
+	url := helpers.ExtractFirstURL(content)
+	if url == "" {
+		return nil
+	}
+
+	preview, _, err := database.GetLinkPreview(url)
+	if err != nil {
+		log.Println("Error reading cached link preview:", err)
+		return nil
+	}
+	return usableLinkPreview(preview)
+}
+
+// usableLinkPreview drops previews of pages that had nothing to show
+func usableLinkPreview(preview *structs.LinkPreview) *structs.LinkPreview {
+	if preview == nil || (preview.Title == "" && preview.Image == "") {
+		return nil
+	}
+	return preview
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/helpers"
+	"social-network/structs"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadLinkPreviewDoesNotWaitForFetch(t *testing.T) {
+	openTestDB(t)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta property="og:title" content="Slow page">`))
+	}))
+	defer server.Close()
+
+	defaultFetcher := linkPreviewFetcher
+	linkPreviewFetcher = &helpers.HTTPLinkPreviewFetcher{Client: server.Client(), MaxBytes: 1024}
+	defer func() { linkPreviewFetcher = defaultFetcher }()
+
+	fetched := make(chan *structs.LinkPreview, 1)
+	start := time.Now()
+	preview := loadLinkPreview("look at "+server.URL+"/page", func(preview *structs.LinkPreview) {
+		fetched <- preview
+	})
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("loadLinkPreview waited %v for the page", elapsed)
+	}
+	if preview != nil {
+		t.Fatal("preview returned before the page was fetched")
+	}
+
+	close(release)
+	select {
+	case preview = <-fetched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fetched preview was never delivered")
+	}
+	if preview.Title != "Slow page" {
+		t.Fatalf("title = %q, want %q", preview.Title, "Slow page")
+	}
+	if cached := cachedLinkPreview(server.URL + "/page"); cached == nil || cached.Title != "Slow page" {
+		t.Fatal("the fetched preview wasn't cached")
+	}
+}
+
+func TestLoadLinkPreviewFetchesAPageOnce(t *testing.T) {
+	openTestDB(t)
+
+	var requests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta property="og:title" content="Popular page">`))
+	}))
+	defer server.Close()
+
+	defaultFetcher := linkPreviewFetcher
+	linkPreviewFetcher = &helpers.HTTPLinkPreviewFetcher{Client: server.Client(), MaxBytes: 1024}
+	defer func() { linkPreviewFetcher = defaultFetcher }()
+
+	const publishes = 20
+	fetched := make(chan *structs.LinkPreview, publishes)
+	for i := 0; i < publishes; i++ {
+		loadLinkPreview("look at "+server.URL+"/popular", func(preview *structs.LinkPreview) {
+			fetched <- preview
+		})
+	}
+	close(release)
+
+	for i := 0; i < publishes; i++ {
+		select {
+		case preview := <-fetched:
+			if preview.Title != "Popular page" {
+				t.Fatalf("title = %q, want %q", preview.Title, "Popular page")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d of %d publishes got the preview", i, publishes)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("the page was fetched %d times, want once", n)
+	}
+}
@@ -39,6 +39,16 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	suspendedUntil, err := database.GetUserSuspension(user.Id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if suspendedUntil != nil {
+		http.Error(w, "Your account is suspended until "+suspendedUntil.Format("2006-01-02 15:04 MST"), http.StatusForbidden)
+		return
+	}
+
 	err = database.DeleteUserFromSessions(user.Id)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -53,6 +63,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		NickName:   user.Nickname,
 		SessionId:  session.SessionToken,
 		Expiration: session.Expiration,
+		Role:       user.Role,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+)
+
+var mentionPlaces = map[string]string{
+	"post":            "a post",
+	"comment":         "a comment",
+	"group_post":      "a group post",
+	"group_comment":   "a group comment",
+	"private_message": "a private message",
+	"group_message":   "a group chat",
+}
+
+// notifyMentions stores a mention link for every user mentioned in the content and sends them a "mention" notification.
+// Users who can't see the content are skipped, so a mention never reveals a post to someone outside its audience.
+func notifyMentions(content string, mention structs.Mention) {
+	notifyMentionList(helpers.ExtractMentions(content), mention)
+}
+
+// notifyNewMentions is used when content is edited, only users who weren't mentioned before are notified
+func notifyNewMentions(oldContent, newContent string, mention structs.Mention) {
+	oldMentions := make(map[string]bool)
+	for _, m := range helpers.ExtractMentions(oldContent) {
+		oldMentions[m] = true
+	}
+	newMentions := make([]string, 0)
+	for _, m := range helpers.ExtractMentions(newContent) {
+		if !oldMentions[m] {
+			newMentions = append(newMentions, m)
+		}
+	}
+	notifyMentionList(newMentions, mention)
+}
+
+func notifyMentionList(mentions []string, mention structs.Mention) {
+	if len(mentions) == 0 {
+		return
+	}
+
+	sender, err := database.GetUserMainInfo(mention.SenderId)
+	if err != nil || sender == nil {
+		log.Println("Error getting mention sender:", err)
+		return
+	}
+	senderName := sender.Nickname
+	if senderName == "" {
+		senderName = sender.FirstName + " " + sender.LastName
+	}
+
+	notified := make(map[int]bool)
+	for _, m := range mentions {
+		userId, err := database.GetUserIdByMention(m)
+		if err != nil {
+			log.Println("Error resolving mention:", err)
+			continue
+		}
+		if userId == 0 || userId == mention.SenderId || notified[userId] {
+			continue
+		}
+
+		canSee, err := canSeeMentionedContent(userId, mention)
+		if err != nil {
+			log.Println("Error checking mention visibility:", err)
+			continue
+		}
+		if !canSee {
+			continue
+		}
+		notified[userId] = true
+
+		mention.UserId = userId
+		if err := database.InsertMention(mention); err != nil {
+			log.Println("Error inserting mention into database:", err)
+			continue
+		}
+
+		notification := structs.Notification{
+			RequesterId: mention.SenderId,
+			ReceiverId:  userId,
+			GroupId:     mention.GroupId,
+			Content:     senderName + " mentioned you in " + mentionPlaces[mention.ContentType],
+			Type:        "mention",
+			Status:      "",
+		}
+		sendNotification(userId, notification)
+	}
+}
+
+func canSeeMentionedContent(userId int, mention structs.Mention) (bool, error) {
+	switch mention.ContentType {
+	case "post", "comment":
+		return database.IsPostVisibleToUser(mention.PostId, userId)
+	case "group_post", "group_comment":
+		return database.CanUserReadGroup(userId, mention.GroupId)
+	case "group_message":
+		return database.CheckUserIfMemberOfGroup(userId, mention.GroupId)
+	case "private_message":
+		user1Id, user2Id, err := database.GetUserIdByPrivateChatId(mention.PrivateChatId)
+		if err != nil {
+			return false, err
+		}
+		return userId == user1Id || userId == user2Id, nil
+	}
+	return false, nil
+}
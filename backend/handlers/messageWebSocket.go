@@ -18,7 +18,7 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-var messageWebsocketClients = make(map[int]*websocket.Conn)
+var messageWebsocketClients = newWebsocketClients()
 
 func MessageWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromURL)
@@ -33,25 +33,84 @@ func MessageWebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	messageWebsocketClients[userId] = conn
+	client, _ := messageWebsocketClients.add(userId, conn)
 
 	for {
 		_, p, err := conn.ReadMessage()
 		if err != nil {
-			delete(messageWebsocketClients, userId)
+			messageWebsocketClients.remove(userId, client)
 			break
 		}
 
 		var chatMessage structs.ChatMessage
 		if err := json.Unmarshal(p, &chatMessage); err != nil {
 			fmt.Println("Error parsing received message:", err)
+			sendChatError(client, "Invalid message")
 			continue
 		}
 		chatMessage.SenderId = userId
 
-		message, _ := database.InsertChatMessage(chatMessage)
+		if chatMessage.GroupChatId != 0 {
+			// the group chat is for members only, whatever the visibility of the group
+			isMember, err := database.CheckUserIfMemberOfGroup(userId, chatMessage.GroupChatId)
+			if err != nil {
+				fmt.Println("Error checking group membership:", err)
+				sendChatError(client, "Your message couldn't be sent")
+				continue
+			}
+			if !isMember {
+				sendChatError(client, "You're not a member of this group")
+				continue
+			}
+			// archived groups are read-only, their chat included
+			archived, err := database.IsGroupArchived(chatMessage.GroupChatId)
+			if err != nil {
+				fmt.Println("Error checking group archive:", err)
+				sendChatError(client, "Your message couldn't be sent")
+				continue
+			}
+			if archived {
+				sendChatError(client, "This group is archived and can't be changed")
+				continue
+			}
+		}
+
+		message, err := database.InsertChatMessage(chatMessage)
+		if err != nil {
+			fmt.Println("Error saving chat message:", err)
+			sendChatError(client, "Your message couldn't be sent")
+			continue
+		}
+		message.LinkPreview = loadLinkPreview(message.Content, func(preview *structs.LinkPreview) {
+			sendChatLinkPreview(message, preview)
+		})
 
 		sendMessageToUsers(message)
+		notifyChatMentions(message)
+	}
+}
+
+// sendChatError tells the sender why their message wasn't sent, on their own connection only
+func sendChatError(client *websocketClient, message string) {
+	frame, err := json.Marshal(structs.ErrorResponse{Status: "error", Message: message})
+	if err != nil {
+		fmt.Println("Error marshalling chat error:", err)
+		return
+	}
+	if err := client.send(frame); err != nil {
+		fmt.Println("Error sending chat error:", err)
+	}
+}
+
+// sendChatLinkPreview pushes the preview of a message that was sent before its link was fetched
+func sendChatLinkPreview(chatMessage structs.ChatMessage, preview *structs.LinkPreview) {
+	update, err := json.Marshal(structs.ChatLinkPreview{Type: "link_preview", MessageId: chatMessage.Id, LinkPreview: *preview})
+	if err != nil {
+		fmt.Println("Error marshalling link preview:", err)
+		return
+	}
+	for _, userId := range chatMemberIds(chatMessage) {
+		sendPrivateMessageToUser(userId, update)
 	}
 }
 
@@ -62,35 +121,54 @@ func sendMessageToUsers(chatMessage structs.ChatMessage) {
 		return
 	}
 
+	for _, userId := range chatMemberIds(chatMessage) {
+		sendPrivateMessageToUser(userId, message)
+	}
+}
+
+// chatMemberIds returns the users of the private or group chat the message was sent to
+func chatMemberIds(chatMessage structs.ChatMessage) []int {
 	if chatMessage.PrivateChatId != 0 {
 		user1Id, user2Id, err := database.GetUserIdByPrivateChatId(chatMessage.PrivateChatId)
 		if err != nil {
 			fmt.Println("Error getting user IDs for private chat:", err)
-			return
+			return nil
 		}
-
-		sendPrivateMessageToUser(user1Id, message)
-		sendPrivateMessageToUser(user2Id, message)
+		return []int{user1Id, user2Id}
 
 	} else if chatMessage.GroupChatId != 0 {
 		userIds, err := database.GetUserIdByGroupChatId(chatMessage.GroupChatId)
 		if err != nil {
 			fmt.Println("Error getting user IDs for group chat:", err)
-			return
+			return nil
 		}
+		return userIds
+	}
+	return nil
+}
 
-		for _, userId := range userIds {
-			sendPrivateMessageToUser(userId, message)
-		}
+func notifyChatMentions(chatMessage structs.ChatMessage) {
+	mention := structs.Mention{
+		SenderId:  chatMessage.SenderId,
+		ContentId: chatMessage.Id,
+	}
+	if chatMessage.PrivateChatId != 0 {
+		mention.ContentType = "private_message"
+		mention.PrivateChatId = chatMessage.PrivateChatId
+	} else if chatMessage.GroupChatId != 0 {
+		mention.ContentType = "group_message"
+		mention.GroupId = chatMessage.GroupChatId
+	} else {
+		return
 	}
+	notifyMentions(chatMessage.Content, mention)
 }
 
 func sendPrivateMessageToUser(userId int, message []byte) {
-	client, exists := messageWebsocketClients[userId]
-	if exists {
-		if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
+	if client := messageWebsocketClients.get(userId); client != nil {
+		if err := client.send(message); err != nil {
 			fmt.Println("Error sending message to client:", err)
-			delete(messageWebsocketClients, userId)
+			messageWebsocketClients.remove(userId, client)
 		}
 	}
 }
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"social-network/structs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// sendTestChatMessage sends the message over the chat websocket as the user and returns the first frame sent back
+func sendTestChatMessage(t *testing.T, userId int, message structs.ChatMessage) []byte {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(MessageWebSocketHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?authorization=" + loginTestUser(t, userId)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(message); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, frame, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+func TestMessageWebSocketRejectsArchivedGroup(t *testing.T) {
+	openTestDB(t)
+	if err := database.SetGroupArchived(1, true); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := sendTestChatMessage(t, 2, structs.ChatMessage{Content: "hello", GroupChatId: 1})
+	want := `{"status":"error","message":"This group is archived and can't be changed"}`
+	if strings.TrimSpace(string(frame)) != want {
+		t.Fatalf("received %s, want %s", frame, want)
+	}
+}
+
+func TestMessageWebSocketRejectsNonMembers(t *testing.T) {
+	openTestDB(t)
+	userId := createTestUser(t, "outsider@test.com")
+
+	frame := sendTestChatMessage(t, userId, structs.ChatMessage{Content: "hello", GroupChatId: 1})
+	want := `{"status":"error","message":"You're not a member of this group"}`
+	if strings.TrimSpace(string(frame)) != want {
+		t.Fatalf("received %s, want %s", frame, want)
+	}
+}
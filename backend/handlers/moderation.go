@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// authenticateModerator is AuthenticateUserAndGetId for the moderation queue, only moderators and admins get through
+func authenticateModerator(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return 0, false
+	}
+
+	role, err := database.GetUserRole(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return 0, false
+	}
+	if !isModeratorRole(role) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return 0, false
+	}
+	return userId, true
+}
+
+func isModeratorRole(role string) bool {
+	return role == "moderator" || role == "admin"
+}
+
+// ReadReports lists the moderation queue. ?status= is open (the default), actioned, dismissed or all,
+// ?assignee= is me, unassigned or a moderator's id.
+func ReadReports(w http.ResponseWriter, r *http.Request) {
+	userId, isModerator := authenticateModerator(w, r)
+	if !isModerator {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = "open"
+	case "all":
+		status = ""
+	case "open", "actioned", "dismissed":
+	default:
+		http.Error(w, "Invalid report status", http.StatusBadRequest)
+		return
+	}
+
+	assigneeId := -1
+	switch assignee := r.URL.Query().Get("assignee"); assignee {
+	case "":
+	case "me":
+		assigneeId = userId
+	case "unassigned":
+		assigneeId = 0
+	default:
+		id, err := strconv.Atoi(assignee)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid assignee", http.StatusBadRequest)
+			return
+		}
+		assigneeId = id
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	reports, err := database.ReadReports(status, assigneeId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+func ReadReport(w http.ResponseWriter, r *http.Request) {
+	_, isModerator := authenticateModerator(w, r)
+	if !isModerator {
+		return
+	}
+
+	report, ok := getReportFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// AssignReport hands an open report to a moderator, with no assignee in the body the report is taken by the caller
+func AssignReport(w http.ResponseWriter, r *http.Request) {
+	userId, isModerator := authenticateModerator(w, r)
+	if !isModerator {
+		return
+	}
+
+	report, ok := getReportFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var assignment structs.ReportAssignment
+	if err := helpers.DecodeJSONBody(r, &assignment); err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if assignment.AssigneeId == 0 {
+		assignment.AssigneeId = userId
+	}
+
+	role, err := database.GetUserRole(assignment.AssigneeId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !isModeratorRole(role) {
+		helpers.ReturnMessageJSON(w, "Reports can only be assigned to moderators", http.StatusBadRequest, "error")
+		return
+	}
+
+	assigned, err := database.AssignReport(report.Id, assignment.AssigneeId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !assigned {
+		helpers.ReturnMessageJSON(w, "The report has already been resolved", http.StatusBadRequest, "error")
+		return
+	}
+
+	recordAudit(userId, "assign_report", "report", report.Id, "assignee "+strconv.Itoa(assignment.AssigneeId))
+
+	if assignment.AssigneeId != userId {
+		sendNotification(assignment.AssigneeId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  assignment.AssigneeId,
+			Content:     "A report was assigned to you",
+			Type:        "report_assigned",
+			Status:      "",
+		})
+	}
+
+	helpers.ReturnMessageJSON(w, "Report assigned", http.StatusOK, "success")
+}
+
+// ResolveReport takes an action on the reported content or its author and closes every open report on it.
+// The reporters are told whether something was done, the reported user is told when it affects them.
+func ResolveReport(w http.ResponseWriter, r *http.Request) {
+	userId, isModerator := authenticateModerator(w, r)
+	if !isModerator {
+		return
+	}
+
+	report, ok := getReportFromURL(w, r)
+	if !ok {
+		return
+	}
+	if report.Status != "open" {
+		helpers.ReturnMessageJSON(w, "The report has already been resolved", http.StatusBadRequest, "error")
+		return
+	}
+
+	var resolution structs.ReportResolution
+	if err := helpers.DecodeJSONBody(r, &resolution); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	resolution.Note = strings.TrimSpace(resolution.Note)
+
+	switch resolution.Action {
+	case "none":
+		report.Status = "dismissed"
+
+	case "hide_content":
+		if report.ContentType == "user" {
+			http.Error(w, "Profiles can't be hidden", http.StatusBadRequest)
+			return
+		}
+		if err := database.HideContent(report.ContentType, report.ContentId); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if report.ContentType == "group_post" {
+			recordGroupPostHidden(userId, report.ContentId, resolution.Note)
+		}
+		sendNotification(report.ReportedUserId, structs.Notification{
+			RequesterId: report.ReportedUserId,
+			ReceiverId:  report.ReportedUserId,
+			Content:     moderationMessage("Something you posted was hidden by a moderator", resolution.Note),
+			Type:        "content_hidden",
+			Status:      "",
+		})
+		report.Status = "actioned"
+
+	case "warn_user":
+		sendNotification(report.ReportedUserId, structs.Notification{
+			RequesterId: report.ReportedUserId,
+			ReceiverId:  report.ReportedUserId,
+			Content:     moderationMessage("You received a warning from a moderator", resolution.Note),
+			Type:        "moderation_warning",
+			Status:      "",
+		})
+		report.Status = "actioned"
+
+	case "suspend_user":
+		if resolution.SuspendDays == 0 {
+			resolution.SuspendDays = 7
+		}
+		if resolution.SuspendDays < 1 || resolution.SuspendDays > 365 {
+			http.Error(w, "Suspensions last between 1 and 365 days", http.StatusBadRequest)
+			return
+		}
+		role, err := database.GetUserRole(report.ReportedUserId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if isModeratorRole(role) {
+			helpers.ReturnMessageJSON(w, "Moderators can't be suspended", http.StatusBadRequest, "error")
+			return
+		}
+		until := time.Now().AddDate(0, 0, resolution.SuspendDays)
+		if err := database.SuspendUser(report.ReportedUserId, until); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		report.Status = "actioned"
+
+	default:
+		http.Error(w, "Invalid moderation action", http.StatusBadRequest)
+		return
+	}
+
+	report.Action = resolution.Action
+	report.ResolutionNote = resolution.Note
+	report.ResolvedBy = userId
+	reporterIds, err := database.ResolveReports(*report)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(userId, "resolve_report", "report", report.Id, moderationMessage(resolution.Action, resolution.Note))
+
+	outcome := "Thanks for your report. A moderator reviewed it and took action"
+	if report.Status == "dismissed" {
+		outcome = "Thanks for your report. A moderator reviewed it and found no violation"
+	}
+	for _, reporterId := range reporterIds {
+		sendNotification(reporterId, structs.Notification{
+			RequesterId: reporterId,
+			ReceiverId:  reporterId,
+			Content:     outcome,
+			Type:        "report_resolved",
+			Status:      "",
+		})
+	}
+
+	resolved, err := database.GetReportById(report.Id)
+	if err != nil || resolved == nil {
+		log.Println("Error reading resolved report:", err)
+		resolved = report
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resolved)
+}
+
+func moderationMessage(message, note string) string {
+	if note == "" {
+		return message
+	}
+	return message + ": " + note
+}
+
+func getReportFromURL(w http.ResponseWriter, r *http.Request) (*structs.Report, bool) {
+	vars := mux.Vars(r)
+	reportId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid report ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	report, err := database.GetReportById(reportId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if report == nil {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return nil, false
+	}
+	return report, true
+}
@@ -8,11 +8,9 @@ import (
 	"social-network/database"
 	"social-network/helpers"
 	"social-network/structs"
-
-	"github.com/gorilla/websocket"
 )
 
-var notificationClients = make(map[int]*websocket.Conn)
+var notificationClients = newWebsocketClients()
 
 func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromURL)
@@ -25,17 +23,16 @@ func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("WebSocket upgrade failed: %s\n", err)
 		return
 	}
+	client, replaced := notificationClients.add(userId, conn)
 	defer func() {
 		conn.Close()
-		delete(notificationClients, userId)
+		notificationClients.remove(userId, client)
 	}()
 
-	if existingConn, ok := notificationClients[userId]; ok {
-		existingConn.Close()
+	if replaced != nil {
+		replaced.conn.Close()
 	}
 
-	notificationClients[userId] = conn
-
 	// Handle offline notifications if any
 	offlineNotifications, err := database.GetOfflineGroupJoinRequestNotifications(userId)
 	if err != nil {
@@ -191,18 +188,18 @@ func sendNotificationViaWebSocket(notification structs.Notification) {
 		log.Println("Error encoding notification JSON:", err)
 		return
 	}
-	recipientConn := getWebSocketConnection(notification.ReceiverId)
-	if recipientConn == nil {
+	recipient := getWebSocketConnection(notification.ReceiverId)
+	if recipient == nil {
 		log.Println("Recipient WebSocket connection not found")
 		return
 	}
 
-	err = recipientConn.WriteMessage(websocket.TextMessage, notificationJSON)
+	err = recipient.send(notificationJSON)
 	if err != nil {
 		log.Println("Error sending notification:", err)
 	}
 }
 
-func getWebSocketConnection(userID int) *websocket.Conn {
-	return notificationClients[userID]
+func getWebSocketConnection(userID int) *websocketClient {
+	return notificationClients.get(userID)
 }
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const maxPinnedPosts = 3
+
+func PinPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnPublishedPost(w, r, userId)
+	if !ok {
+		return
+	}
+	if post.Pinned {
+		helpers.ReturnMessageJSON(w, "The post is already pinned", http.StatusBadRequest, "error")
+		return
+	}
+
+	pinned, err := database.PinPost(post.Id, userId, maxPinnedPosts)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !pinned {
+		helpers.ReturnMessageJSON(w, "You can pin up to "+strconv.Itoa(maxPinnedPosts)+" posts", http.StatusBadRequest, "error")
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Post pinned", http.StatusOK, "success")
+}
+
+func UnpinPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnPublishedPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.UnpinPost(post.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Post unpinned", http.StatusOK, "success")
+}
+
+// PinGroupPost lets the group owner pin a post as an announcement, the other members are notified
+func PinGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, group, ok := getOwnedGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+	if post.Pinned {
+		helpers.ReturnMessageJSON(w, "The post is already pinned", http.StatusBadRequest, "error")
+		return
+	}
+
+	if err := database.PinGroupPost(post.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	for _, memberId := range group.Members {
+		if memberId == userId {
+			continue
+		}
+		sendNotification(memberId, structs.Notification{
+			RequesterId: userId,
+			ReceiverId:  memberId,
+			GroupId:     group.Id,
+			Content:     "New announcement in " + group.Name + ": " + post.Title,
+			Type:        "announcement",
+			Status:      "",
+		})
+	}
+
+	helpers.ReturnMessageJSON(w, "Post pinned", http.StatusOK, "success")
+}
+
+func UnpinGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, _, ok := getOwnedGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.UnpinGroupPost(post.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Post unpinned", http.StatusOK, "success")
+}
+
+func getOwnPublishedPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.Post, bool) {
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	post, err := database.GetPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil || post.UserId != userId || post.Status != "published" {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	return post, true
+}
+
+// getOwnedGroupPost reads the group post from the URL and makes sure the user owns its group
+func getOwnedGroupPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupPost, *structs.Group, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	post, err := database.GetGroupPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if post == nil || post.GroupId != groupId || post.Status != "published" {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if group.CreatorId != userId {
+		http.Error(w, "Only the group owner can pin announcements", http.StatusForbidden)
+		return nil, nil, false
+	}
+	if group.Archived {
+		helpers.ReturnMessageJSON(w, "This group is archived and can't be changed", http.StatusForbidden, "error")
+		return nil, nil, false
+	}
+	return post, group, true
+}
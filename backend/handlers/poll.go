@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// checkPoll validates a poll sent with a new post and fills in the defaults
+func checkPoll(poll *structs.Poll, publishAt *time.Time) error {
+	options := make([]structs.PollOption, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		option.Text = strings.TrimSpace(option.Text)
+		if option.Text == "" {
+			return errors.New("Poll options can't be empty")
+		}
+		options = append(options, structs.PollOption{Text: option.Text})
+	}
+	if len(options) < 2 || len(options) > 10 {
+		return errors.New("A poll needs between 2 and 10 options")
+	}
+	poll.Options = options
+
+	switch poll.ShowResults {
+	case "":
+		poll.ShowResults = "after_vote"
+	case "after_vote", "always":
+	default:
+		return errors.New("Invalid poll results setting")
+	}
+
+	if poll.ClosesAt != nil {
+		if !poll.ClosesAt.After(time.Now()) {
+			return errors.New("The poll closing time has to be in the future")
+		}
+		if publishAt != nil && !poll.ClosesAt.After(*publishAt) {
+			return errors.New("The poll has to close after the post is published")
+		}
+		closesAt := poll.ClosesAt.UTC()
+		poll.ClosesAt = &closesAt
+	}
+	return nil
+}
+
+// getPollForViewer loads the poll of a post as the given user should see it.
+// When the poll is set up that way vote counts stay hidden until the user votes,
+// the author always sees them and everyone sees them once the poll is closed.
+func getPollForViewer(post structs.Post, viewerId int) (*structs.Poll, error) {
+	poll, err := database.GetPollByPostId(post.Id, viewerId)
+	if err != nil || poll == nil {
+		return nil, err
+	}
+
+	poll.IsClosed = poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now())
+	if poll.ShowResults == "after_vote" && !poll.HasVoted && !poll.IsClosed && post.UserId != viewerId {
+		poll.ResultsHidden = true
+		poll.TotalVoters = 0
+		for i := range poll.Options {
+			poll.Options[i].Votes = 0
+		}
+	}
+	return poll, nil
+}
+
+// attachPoll adds the viewer's view of the poll to the post if it has one
+func attachPoll(post *structs.Post, viewerId int) error {
+	poll, err := getPollForViewer(*post, viewerId)
+	if err != nil {
+		return err
+	}
+	post.Poll = poll
+	return nil
+}
+
+func ReadPoll(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getVisiblePollPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	poll, err := getPollForViewer(*post, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if poll == nil {
+		http.Error(w, "Poll not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poll)
+}
+
+func VotePoll(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getVisiblePollPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	var vote structs.PollVote
+	if err := helpers.DecodeJSONBody(r, &vote); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := getPollForViewer(*post, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if poll == nil {
+		http.Error(w, "Poll not found", http.StatusNotFound)
+		return
+	}
+	if poll.IsClosed {
+		helpers.ReturnMessageJSON(w, "The poll is closed", http.StatusBadRequest, "error")
+		return
+	}
+	if poll.HasVoted {
+		helpers.ReturnMessageJSON(w, database.ErrAlreadyVoted.Error(), http.StatusBadRequest, "error")
+		return
+	}
+
+	if len(vote.OptionIds) == 0 || (!poll.MultipleChoice && len(vote.OptionIds) > 1) {
+		helpers.ReturnMessageJSON(w, "Please choose an option", http.StatusBadRequest, "error")
+		return
+	}
+	pollOptions := make(map[int]bool)
+	for _, option := range poll.Options {
+		pollOptions[option.Id] = true
+	}
+	chosen := make(map[int]bool)
+	for _, optionId := range vote.OptionIds {
+		if !pollOptions[optionId] || chosen[optionId] {
+			helpers.ReturnMessageJSON(w, "Invalid poll option", http.StatusBadRequest, "error")
+			return
+		}
+		chosen[optionId] = true
+	}
+
+	err = database.InsertPollVotes(poll.Id, userId, vote.OptionIds)
+	if err == database.ErrAlreadyVoted {
+		helpers.ReturnMessageJSON(w, err.Error(), http.StatusBadRequest, "error")
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	poll, err = getPollForViewer(*post, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	broadcastPollUpdate(*post)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poll)
+}
+
+// getVisiblePollPost reads the post from the URL and makes sure the user is in its audience
+func getVisiblePollPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.Post, bool) {
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	visible, err := database.IsPostVisibleToUser(postId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !visible {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	post, err := database.GetPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	return post, true
+}
+
+// broadcastPollUpdate pushes the new results to the connected users who can see the post.
+// Each user gets their own view, so results stay hidden from those who haven't voted yet.
+func broadcastPollUpdate(post structs.Post) {
+	for _, userId := range notificationClients.userIds() {
+		visible, err := database.IsPostVisibleToUser(post.Id, userId)
+		if err != nil {
+			log.Println("Error checking poll visibility:", err)
+			continue
+		}
+		if !visible {
+			continue
+		}
+
+		poll, err := getPollForViewer(post, userId)
+		if err != nil || poll == nil {
+			log.Println("Error reading poll for update:", err)
+			continue
+		}
+		if poll.ResultsHidden {
+			continue
+		}
+
+		update, err := json.Marshal(structs.PollUpdate{Type: "poll_update", PostId: post.Id, Poll: *poll})
+		if err != nil {
+			log.Println("Error encoding poll update:", err)
+			continue
+		}
+		if client := notificationClients.get(userId); client != nil {
+			if err := client.send(update); err != nil {
+				log.Println("Error sending poll update:", err)
+			}
+		}
+	}
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"social-network/database"
 	"social-network/helpers"
@@ -29,14 +31,19 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Please provide a title and content", http.StatusBadRequest)
 		return
 	}
-
-	UserInfo, err := database.GetUserById(userId)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err := checkPostStatus(creationPostInfo.Status, creationPostInfo.PublishAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	creationPostInfo.PublishAt = normalizePublishTime(creationPostInfo.Status, creationPostInfo.PublishAt)
+	if creationPostInfo.Poll != nil {
+		if err := checkPoll(creationPostInfo.Poll, creationPostInfo.PublishAt); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	creationPostInfo.UserId = userId
-	creationPostInfo.ProfilePicture = UserInfo.Avatar
 
 	posts, err := database.AddPost(creationPostInfo)
 	if err != nil {
@@ -44,6 +51,26 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if creationPostInfo.Poll != nil {
+		creationPostInfo.Poll.PostId = posts.Id
+		if _, err := database.InsertPoll(*creationPostInfo.Poll); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if posts.Status == "published" {
+		if err := announcePost(posts); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := attachPostDetail(&posts, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(posts); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -69,6 +96,12 @@ func ReadPosts(w http.ResponseWriter, r *http.Request) {
 	posts, err := database.ReadAllPosts(loggedInUserId)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := attachPostDetails(posts, loggedInUserId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -76,6 +109,210 @@ func ReadPosts(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func Repost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	// the body is optional, a plain repost doesn't need any commentary
+	var repostInfo structs.Post
+	err = json.NewDecoder(r.Body).Decode(&repostInfo)
+	if err != nil && err != io.EOF {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	original, err := database.GetPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	// sharing a plain repost shares the post it points to
+	if original != nil && original.RepostOf != 0 && original.Content == "" {
+		original, err = database.GetPostById(original.RepostOf)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if original == nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+	if original.Status != "published" {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+	if original.Privacy != "public" {
+		http.Error(w, "Only public posts can be shared", http.StatusForbidden)
+		return
+	}
+	if original.UserId == userId {
+		http.Error(w, "You can't repost your own post", http.StatusBadRequest)
+		return
+	}
+
+	if repostInfo.Content == "" {
+		reposted, err := database.HasUserReposted(userId, original.Id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if reposted {
+			http.Error(w, "You have already reposted this post", http.StatusBadRequest)
+			return
+		}
+	}
+
+	repostInfo.UserId = userId
+	repostInfo.RepostOf = original.Id
+	repostInfo.Status = "published"
+	repostInfo.PublishAt = nil
+	repostInfo.Poll = nil
+	if repostInfo.Privacy == "" {
+		// reposts go to the reposter's followers unless asked otherwise
+		repostInfo.Privacy = "private"
+	}
+
+	post, err := database.AddPost(repostInfo)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := announcePost(post); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := attachPostDetail(&post, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(post); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func DeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	post, err := database.GetPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if post == nil {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+	if post.UserId != userId {
+		helpers.ReturnMessageJSON(w, "You can only delete your own posts", http.StatusForbidden, "error")
+		return
+	}
+
+	if err := database.DeletePost(postId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Post deleted", http.StatusOK, "success")
+}
+
+// DeleteGroupPostHandler deletes a group post, authors can delete their own and group moderators anyone's
+func DeleteGroupPostHandler(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	role, err := database.GetGroupMemberRole(userId, groupId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if role == "" {
+		helpers.ReturnMessageJSON(w, "You aren't a member of this group", http.StatusBadRequest, "error")
+		return
+	}
+
+	post, err := database.GetGroupPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	// other people's drafts and scheduled posts aren't visible, so they can't be deleted either
+	if post == nil || post.GroupId != groupId || (post.UserId != userId && post.Status != "published" && post.Status != "hidden") {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return
+	}
+	if post.UserId != userId && !groupRoleAllows(role, "delete_content") {
+		helpers.ReturnMessageJSON(w, "You can only delete your own posts", http.StatusForbidden, "error")
+		return
+	}
+
+	if err := database.DeleteGroupPost(postId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	recordGroupAudit(groupId, userId, "post_deleted", "group_post", postId, "author "+strconv.Itoa(post.UserId))
+
+	if post.UserId != userId {
+		notifyGroupContentRemoved(userId, post.UserId, groupId, "Your post '"+post.Title+"'")
+	}
+
+	helpers.ReturnMessageJSON(w, "Post deleted", http.StatusOK, "success")
+}
+
+// notifyGroupContentRemoved tells the author that a group moderator deleted their post or comment
+func notifyGroupContentRemoved(moderatorId, authorId, groupId int, content string) {
+	group, err := database.ReadGroup(groupId)
+	if err != nil {
+		log.Println("Error reading group:", err)
+		return
+	}
+	sendNotification(authorId, structs.Notification{
+		RequesterId: moderatorId,
+		ReceiverId:  authorId,
+		GroupId:     groupId,
+		Content:     content + " was removed from the group '" + group.Name + "' by a moderator",
+		Type:        "group_content_removed",
+		Status:      "",
+	})
+}
+
 func CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
 	if !isAuthenticated {
@@ -91,17 +328,16 @@ func CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Please provide a title and content", http.StatusBadRequest)
 		return
 	}
-
-	UserInfo, err := database.GetUserById(userId)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if err := checkPostStatus(postInfo.Status, postInfo.PublishAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	postInfo.PublishAt = normalizePublishTime(postInfo.Status, postInfo.PublishAt)
 
 	vars := mux.Vars(r)
 	groupId, _ := strconv.Atoi(vars["id"])
 	postInfo.GroupId = groupId
 	postInfo.UserId = userId
-	postInfo.ProfilePicture = UserInfo.Avatar
 
 	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
 	if err != nil {
@@ -113,6 +349,16 @@ func CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "You aren't a member of this group", http.StatusBadRequest)
 		return
 	}
+	if !checkGroupNotArchived(w, groupId) {
+		return
+	}
+	if postInfo.Status == "" || postInfo.Status == "published" {
+		postInfo.Status, err = groupPostPublishStatus(userId, groupId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
 
 	posts, err := database.AddGroupPost(postInfo)
 	if err != nil {
@@ -120,6 +366,16 @@ func CreateGroupPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if posts.Status == "published" {
+		if err := announceGroupPost(posts); err != nil {
+			http.Error(w, "Failed to create group post", http.StatusInternalServerError)
+			return
+		}
+	} else if posts.Status == "pending" {
+		notifyPostAwaitingApproval(posts)
+	}
+
+	renderGroupPostContent(&posts)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(posts); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -138,23 +394,17 @@ func ReadGroupPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	if !isMember {
-		http.Error(w, "You aren't a member of this group", http.StatusBadRequest)
+	if !checkGroupReadable(w, userId, groupId) {
 		return
 	}
 
-	groupPosts, err := database.ReadAllGroupPosts(groupId)
+	groupPosts, err := database.ReadAllGroupPosts(groupId, userId)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to retrieve group posts: %v", err), http.StatusInternalServerError)
 		return
 	}
 
+	renderGroupPostsContent(groupPosts)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(groupPosts)
 }
@@ -179,3 +429,57 @@ func ReadUserInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// announcePost indexes the tags of a post that just went live and notifies the users mentioned in it
+func announcePost(post structs.Post) error {
+	if err := database.InsertPostTags(post.Id, helpers.ExtractHashtags(post.Content)); err != nil {
+		return err
+	}
+	notifyMentions(post.Content, structs.Mention{
+		SenderId:    post.UserId,
+		ContentType: "post",
+		ContentId:   post.Id,
+		PostId:      post.Id,
+	})
+	loadLinkPreview(post.Content, nil)
+	return nil
+}
+
+func announceGroupPost(post structs.GroupPost) error {
+	if err := database.InsertGroupPostTags(post.Id, post.GroupId, helpers.ExtractHashtags(post.Content)); err != nil {
+		return err
+	}
+	notifyMentions(post.Content, structs.Mention{
+		SenderId:    post.UserId,
+		ContentType: "group_post",
+		ContentId:   post.Id,
+		PostId:      post.Id,
+		GroupId:     post.GroupId,
+	})
+	return nil
+}
+
+// attachPostDetails adds what's stored next to the posts: the viewer's view of polls, cached link previews
+// and the rendered content of the posts and their comments, for shared originals too
+func attachPostDetails(posts []structs.Post, viewerId int) error {
+	for i := range posts {
+		if err := attachPostDetail(&posts[i], viewerId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attachPostDetail(post *structs.Post, viewerId int) error {
+	if err := attachPoll(post, viewerId); err != nil {
+		return err
+	}
+	post.LinkPreview = cachedLinkPreview(post.Content)
+	post.ContentHTML = helpers.RenderMarkdown(post.Content)
+	renderCommentsContent(post.Comments)
+
+	if post.Original != nil {
+		return attachPostDetail(post.Original, viewerId)
+	}
+	return nil
+}
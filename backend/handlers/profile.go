@@ -30,7 +30,12 @@ func LoggedInUserProfileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := database.GetGroupByUserId(loggedInUserId)
+	if err := attachPostDetails(posts, loggedInUserId); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	groups, err := database.GetGroupByUserId(loggedInUserId, loggedInUserId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -68,7 +73,14 @@ func OtherUserProfileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	groups, err := database.GetGroupByUserId(otherUserId)
+	// the profile can be opened without logging in, poll results are then shown as to someone who hasn't voted
+	viewerId, _ := database.GetUserIdAndAuthStatus(r.Header.Get("Authorization"))
+	if err := attachPostDetails(posts, viewerId); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	groups, err := database.GetGroupByUserId(otherUserId, viewerId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
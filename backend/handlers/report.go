@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strings"
+	"unicode/utf8"
+)
+
+var reportContentTypes = map[string]bool{
+	"post":          true,
+	"comment":       true,
+	"group_post":    true,
+	"group_comment": true,
+	"message":       true,
+	"user":          true,
+}
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate_speech":    true,
+	"violence":       true,
+	"nudity":         true,
+	"misinformation": true,
+	"other":          true,
+}
+
+func CreateReport(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	var report structs.Report
+	if err := helpers.DecodeJSONBody(r, &report); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if !reportContentTypes[report.ContentType] {
+		http.Error(w, "Invalid content type", http.StatusBadRequest)
+		return
+	}
+	if !reportReasons[report.Reason] {
+		http.Error(w, "Invalid report reason", http.StatusBadRequest)
+		return
+	}
+	report.Details = strings.TrimSpace(report.Details)
+	if report.Reason == "other" && report.Details == "" {
+		http.Error(w, "Please describe the problem", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(report.Details) > 1000 {
+		http.Error(w, "The description is too long", http.StatusBadRequest)
+		return
+	}
+
+	reportedUserId, snapshot, found, err := findReportedContent(report.ContentType, report.ContentId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Content not found", http.StatusNotFound)
+		return
+	}
+	if reportedUserId == userId {
+		helpers.ReturnMessageJSON(w, "You can't report yourself", http.StatusBadRequest, "error")
+		return
+	}
+
+	alreadyReported, err := database.HasOpenReport(userId, report.ContentType, report.ContentId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if alreadyReported {
+		helpers.ReturnMessageJSON(w, "You have already reported this", http.StatusBadRequest, "error")
+		return
+	}
+
+	report.ReporterId = userId
+	report.ReportedUserId = reportedUserId
+	report.ContentSnapshot = snapshot
+	savedReport, err := database.InsertReport(report)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(savedReport)
+}
+
+// findReportedContent looks up the reported content and returns its author and a copy of it.
+// Content the user can't see is reported as not found, the same way it is everywhere else.
+func findReportedContent(contentType string, contentId, userId int) (int, string, bool, error) {
+	switch contentType {
+	case "post":
+		visible, err := database.IsPostVisibleToUser(contentId, userId)
+		if err != nil || !visible {
+			return 0, "", false, err
+		}
+		post, err := database.GetPostById(contentId)
+		if err != nil || post == nil {
+			return 0, "", false, err
+		}
+		return post.UserId, post.Title + "\n\n" + post.Content, true, nil
+
+	case "comment":
+		comment, err := database.GetCommentById(contentId)
+		if err != nil || comment == nil {
+			return 0, "", false, err
+		}
+		visible, err := database.IsPostVisibleToUser(comment.PostId, userId)
+		if err != nil || !visible {
+			return 0, "", false, err
+		}
+		return comment.UserId, comment.Content, true, nil
+
+	case "group_post":
+		post, err := database.GetGroupPostById(contentId)
+		if err != nil || post == nil || post.Status != "published" {
+			return 0, "", false, err
+		}
+		readable, err := database.CanUserReadGroup(userId, post.GroupId)
+		if err != nil || !readable {
+			return 0, "", false, err
+		}
+		return post.UserId, post.Title + "\n\n" + post.Content, true, nil
+
+	case "group_comment":
+		comment, err := database.GetGroupCommentById(contentId)
+		if err != nil || comment == nil {
+			return 0, "", false, err
+		}
+		readable, err := database.CanUserReadGroup(userId, comment.GroupId)
+		if err != nil || !readable {
+			return 0, "", false, err
+		}
+		return comment.UserId, comment.Content, true, nil
+
+	case "message":
+		message, err := database.GetChatMessageById(contentId)
+		if err != nil || message == nil {
+			return 0, "", false, err
+		}
+		if message.PrivateChatId != 0 {
+			user1Id, user2Id, err := database.GetUserIdByPrivateChatId(message.PrivateChatId)
+			if err != nil || (user1Id != userId && user2Id != userId) {
+				return 0, "", false, err
+			}
+		} else {
+			isMember, err := database.CheckUserIfMemberOfGroup(userId, message.GroupChatId)
+			if err != nil || !isMember {
+				return 0, "", false, err
+			}
+		}
+		return message.SenderId, message.Content, true, nil
+
+	case "user":
+		user, err := database.GetUserMainInfo(contentId)
+		if err != nil || user == nil {
+			return 0, "", false, err
+		}
+		name := user.FirstName + " " + user.LastName
+		if user.Nickname != "" {
+			name += " (" + user.Nickname + ")"
+		}
+		return user.Id, name, true, nil
+	}
+	return 0, "", false, nil
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"social-network/helpers"
+	"social-network/structs"
+)
+
+// Content is stored as the Markdown the user wrote, the rendered HTML is added just before it's sent,
+// so changes to the renderer apply to old posts as well. Post lists are rendered with the rest of the posts'
+// details in attachPostDetails.
+
+func renderPostContent(post *structs.Post) {
+	post.ContentHTML = helpers.RenderMarkdown(post.Content)
+	renderCommentsContent(post.Comments)
+	if post.Original != nil {
+		renderPostContent(post.Original)
+	}
+}
+
+func renderCommentsContent(comments []structs.Comment) {
+	for i := range comments {
+		comments[i].ContentHTML = helpers.RenderMarkdown(comments[i].Content)
+		renderCommentsContent(comments[i].Replies)
+	}
+}
+
+func renderGroupPostContent(post *structs.GroupPost) {
+	post.ContentHTML = helpers.RenderMarkdown(post.Content)
+	renderGroupCommentsContent(post.Comments)
+}
+
+func renderGroupPostsContent(posts []structs.GroupPost) {
+	for i := range posts {
+		renderGroupPostContent(&posts[i])
+	}
+}
+
+func renderGroupCommentsContent(comments []structs.GroupComment) {
+	for i := range comments {
+		comments[i].ContentHTML = helpers.RenderMarkdown(comments[i].Content)
+		renderGroupCommentsContent(comments[i].Replies)
+	}
+}
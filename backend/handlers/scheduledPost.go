@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// checkPostStatus validates the status a post is created or edited with
+func checkPostStatus(status string, publishAt *time.Time) error {
+	switch status {
+	case "", "published", "draft":
+		return nil
+	case "scheduled":
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return errors.New("Scheduled posts need a publish time in the future")
+		}
+		return nil
+	}
+	return errors.New("Invalid post status")
+}
+
+// normalizePublishTime keeps the publish time only for scheduled posts and stores it in UTC,
+// so it compares correctly with the time the publisher passes to the database
+func normalizePublishTime(status string, publishAt *time.Time) *time.Time {
+	if status != "scheduled" || publishAt == nil {
+		return nil
+	}
+	utc := publishAt.UTC()
+	return &utc
+}
+
+func ReadScheduledPosts(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	posts, err := database.GetUnpublishedPosts(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := attachPostDetails(posts, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	groupPosts, err := database.GetUnpublishedGroupPosts(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderGroupPostsContent(groupPosts)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(structs.ScheduledPosts{Posts: posts, GroupPosts: groupPosts})
+}
+
+func EditScheduledPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnUnpublishedPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	var changes structs.Post
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if changes.Title != "" {
+		post.Title = changes.Title
+	}
+	if changes.Content != "" {
+		post.Content = changes.Content
+	}
+	if changes.Photo != "" {
+		post.Photo = changes.Photo
+	}
+	if changes.Privacy != "" {
+		post.Privacy = changes.Privacy
+	}
+	if changes.Status != "" {
+		post.Status = changes.Status
+	}
+	if changes.PublishAt != nil {
+		post.PublishAt = changes.PublishAt
+	}
+
+	if err := checkPostStatus(post.Status, post.PublishAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	post.PublishAt = normalizePublishTime(post.Status, post.PublishAt)
+
+	updated, err := database.UpdateUnpublishedPost(*post)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		helpers.ReturnMessageJSON(w, "The post has already been published", http.StatusBadRequest, "error")
+		return
+	}
+
+	if post.Status == "published" {
+		if err := announcePost(*post); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	renderPostContent(post)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func CancelScheduledPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnUnpublishedPost(w, r, userId)
+	if !ok {
+		return
+	}
+	if post.Status != "scheduled" {
+		helpers.ReturnMessageJSON(w, "The post isn't scheduled", http.StatusBadRequest, "error")
+		return
+	}
+
+	// a cancelled post is kept as a draft so the author doesn't lose it
+	post.Status = "draft"
+	post.PublishAt = nil
+	updated, err := database.UpdateUnpublishedPost(*post)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		helpers.ReturnMessageJSON(w, "The post has already been published", http.StatusBadRequest, "error")
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Scheduled post moved to drafts", http.StatusOK, "success")
+}
+
+func EditScheduledGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnUnpublishedGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+
+	var changes structs.GroupPost
+	if err := helpers.DecodeJSONBody(r, &changes); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if changes.Title != "" {
+		post.Title = changes.Title
+	}
+	if changes.Content != "" {
+		post.Content = changes.Content
+	}
+	if changes.Photo != "" {
+		post.Photo = changes.Photo
+	}
+	if changes.Status != "" {
+		post.Status = changes.Status
+	}
+	if changes.PublishAt != nil {
+		post.PublishAt = changes.PublishAt
+	}
+
+	if err := checkPostStatus(post.Status, post.PublishAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	post.PublishAt = normalizePublishTime(post.Status, post.PublishAt)
+	if post.Status == "published" {
+		status, err := groupPostPublishStatus(userId, post.GroupId)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		post.Status = status
+	}
+
+	updated, err := database.UpdateUnpublishedGroupPost(*post)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		helpers.ReturnMessageJSON(w, "The post has already been published", http.StatusBadRequest, "error")
+		return
+	}
+
+	if post.Status == "published" {
+		if err := announceGroupPost(*post); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	} else if post.Status == "pending" {
+		notifyPostAwaitingApproval(*post)
+	}
+
+	renderGroupPostContent(post)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(post)
+}
+
+func CancelScheduledGroupPost(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	post, ok := getOwnUnpublishedGroupPost(w, r, userId)
+	if !ok {
+		return
+	}
+	if post.Status != "scheduled" {
+		helpers.ReturnMessageJSON(w, "The post isn't scheduled", http.StatusBadRequest, "error")
+		return
+	}
+
+	post.Status = "draft"
+	post.PublishAt = nil
+	updated, err := database.UpdateUnpublishedGroupPost(*post)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		helpers.ReturnMessageJSON(w, "The post has already been published", http.StatusBadRequest, "error")
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "Scheduled post moved to drafts", http.StatusOK, "success")
+}
+
+func getOwnUnpublishedPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.Post, bool) {
+	vars := mux.Vars(r)
+	postId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	post, err := database.GetPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil || post.UserId != userId {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	if post.Status != "draft" && post.Status != "scheduled" {
+		helpers.ReturnMessageJSON(w, "Only drafts and scheduled posts can be edited", http.StatusBadRequest, "error")
+		return nil, false
+	}
+	return post, true
+}
+
+func getOwnUnpublishedGroupPost(w http.ResponseWriter, r *http.Request, userId int) (*structs.GroupPost, bool) {
+	vars := mux.Vars(r)
+	groupId, err := strconv.Atoi(vars["groupId"])
+	if err != nil {
+		http.Error(w, "Invalid group ID", http.StatusBadRequest)
+		return nil, false
+	}
+	postId, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	post, err := database.GetGroupPostById(postId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if post == nil || post.GroupId != groupId || post.UserId != userId {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil, false
+	}
+	if post.Status != "draft" && post.Status != "scheduled" {
+		helpers.ReturnMessageJSON(w, "Only drafts and scheduled posts can be edited", http.StatusBadRequest, "error")
+		return nil, false
+	}
+	if !checkGroupNotArchived(w, groupId) {
+		return nil, false
+	}
+	return post, true
+}
+
+// RunScheduledPostPublisher publishes due scheduled posts on every tick, it's started from main in its own goroutine
+func RunScheduledPostPublisher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		publishDuePosts(time.Now())
+	}
+}
+
+func publishDuePosts(now time.Time) {
+	postIds, err := database.GetDueScheduledPostIds(now)
+	if err != nil {
+		log.Println("Error fetching scheduled posts:", err)
+	}
+	for _, postId := range postIds {
+		published, err := database.PublishPost(postId)
+		if err != nil {
+			log.Println("Error publishing scheduled post:", err)
+			continue
+		}
+		if !published {
+			continue
+		}
+		post, err := database.GetPostById(postId)
+		if err != nil || post == nil {
+			log.Println("Error reading published post:", err)
+			continue
+		}
+		if err := announcePost(*post); err != nil {
+			log.Println("Error announcing published post:", err)
+		}
+		sendNotification(post.UserId, structs.Notification{
+			RequesterId: post.UserId,
+			ReceiverId:  post.UserId,
+			Content:     "Your scheduled post '" + post.Title + "' was published",
+			Type:        "post_published",
+			Status:      "",
+		})
+	}
+
+	groupPostIds, err := database.GetDueScheduledGroupPostIds(now)
+	if err != nil {
+		log.Println("Error fetching scheduled group posts:", err)
+	}
+	for _, postId := range groupPostIds {
+		post, err := database.GetGroupPostById(postId)
+		if err != nil || post == nil {
+			log.Println("Error reading scheduled group post:", err)
+			continue
+		}
+		// authors who left the group or were banned from it since scheduling the post get it back as a draft
+		canPost, err := canStillPostInGroup(post.UserId, post.GroupId)
+		if err != nil {
+			log.Println("Error checking author of scheduled group post:", err)
+			continue
+		}
+		if !canPost {
+			returnScheduledGroupPostToDrafts(*post)
+			continue
+		}
+		// in groups that approve posts, a due post goes to the approval queue instead
+		status, err := groupPostPublishStatus(post.UserId, post.GroupId)
+		if err != nil {
+			log.Println("Error reading group of scheduled post:", err)
+			continue
+		}
+		published, err := database.PublishGroupPost(postId, status)
+		if err != nil {
+			log.Println("Error publishing scheduled group post:", err)
+			continue
+		}
+		if !published {
+			continue
+		}
+		post.Status = status
+
+		content := "Your scheduled group post '" + post.Title + "' was published"
+		if status == "pending" {
+			content = "Your scheduled group post '" + post.Title + "' is waiting for approval"
+			notifyPostAwaitingApproval(*post)
+		} else if err := announceGroupPost(*post); err != nil {
+			log.Println("Error announcing published group post:", err)
+		}
+		sendNotification(post.UserId, structs.Notification{
+			RequesterId: post.UserId,
+			ReceiverId:  post.UserId,
+			GroupId:     post.GroupId,
+			Content:     content,
+			Type:        "post_published",
+			Status:      "",
+		})
+	}
+}
+
+// canStillPostInGroup reports whether the user is a member of the group and isn't banned from it
+func canStillPostInGroup(userId, groupId int) (bool, error) {
+	isMember, err := database.CheckUserIfMemberOfGroup(userId, groupId)
+	if err != nil || !isMember {
+		return false, err
+	}
+	ban, err := database.GetActiveGroupBan(groupId, userId)
+	if err != nil {
+		return false, err
+	}
+	return ban == nil, nil
+}
+
+func returnScheduledGroupPostToDrafts(post structs.GroupPost) {
+	post.Status = "draft"
+	post.PublishAt = nil
+	updated, err := database.UpdateUnpublishedGroupPost(post)
+	if err != nil {
+		log.Println("Error moving scheduled group post to drafts:", err)
+		return
+	}
+	if !updated {
+		return
+	}
+	sendNotification(post.UserId, structs.Notification{
+		RequesterId: post.UserId,
+		ReceiverId:  post.UserId,
+		GroupId:     post.GroupId,
+		Content:     "Your scheduled group post '" + post.Title + "' wasn't published because you can no longer post in the group, it was moved to your drafts",
+		Type:        "post_unpublished",
+		Status:      "",
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"social-network/database"
+	"social-network/structs"
+	"testing"
+	"time"
+)
+
+func TestPublishDuePostsSkipsAuthorsWhoCantPost(t *testing.T) {
+	openTestDB(t)
+	publishAt := time.Now().Add(-time.Minute)
+	schedule := func(userId int) int {
+		post, err := database.AddGroupPost(structs.GroupPost{GroupId: 1, UserId: userId, Title: "Scheduled", Content: "content", Status: "scheduled", PublishAt: &publishAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return post.Id
+	}
+	ownerPostId := schedule(1)
+	leftPostId := schedule(2)
+	bannedPostId := schedule(3)
+
+	if err := database.DeleteUserFromGroup(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.BanGroupMember(1, 3, 1, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	publishDuePosts(time.Now())
+
+	for postId, want := range map[int]string{ownerPostId: "published", leftPostId: "draft", bannedPostId: "draft"} {
+		post, err := database.GetGroupPostById(postId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if post.Status != want {
+			t.Errorf("post %d status = %q, want %q", postId, post.Status, want)
+		}
+		if want == "draft" && post.PublishAt != nil {
+			t.Errorf("post %d moved to drafts is still scheduled for %v", postId, post.PublishAt)
+		}
+	}
+}
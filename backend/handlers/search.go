@@ -30,7 +30,7 @@ func SearchUsersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// group search
-	groups, err := database.SearchGroup(query)
+	groups, err := database.SearchGroup(query, loggedInUserId)
 	if err != nil {
 		log.Printf("Error searching groups: %v", err)
 		http.Error(w, "Error searching groups", http.StatusInternalServerError)
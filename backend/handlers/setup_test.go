@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"social-network/database"
+	"social-network/structs"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+)
+
+// openTestDB runs the migrations on a fresh database file that is removed with the test. The migrations seed users
+// 1 to 3 and groups 1 to 3, group 1 is owned by user 1 and has users 2 and 3 as members.
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.OpenDB(filepath.Join(t.TempDir(), "test.db"), "file://../database/migrations"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.DB.Close() })
+}
+
+func createTestUser(t *testing.T, email string) int {
+	t.Helper()
+	if err := database.InsertUser("Test", "User", email, "2000-01-01", nil, nil, nil, false, []byte("hash")); err != nil {
+		t.Fatal(err)
+	}
+	user, err := database.GetUserByEmail(email)
+	if err != nil || user == nil {
+		t.Fatalf("reading test user: %v", err)
+	}
+	return user.Id
+}
+
+// loginTestUser creates a session for the user and returns its token
+func loginTestUser(t *testing.T, userId int) string {
+	t.Helper()
+	token, err := uuid.NewV4()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := structs.Session{UserId: userId, SessionToken: token.String(), Expiration: time.Now().Add(time.Hour)}
+	if err := database.InsertSessionToken(session); err != nil {
+		t.Fatal(err)
+	}
+	return session.SessionToken
+}
+
+// doTestRequest sends the request to the handler registered on route the way main does, as the user the token
+// belongs to, and returns the response. A non-nil body is sent as JSON.
+func doTestRequest(t *testing.T, handler http.HandlerFunc, route, method, url, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc(route, handler).Methods(method)
+	req := httptest.NewRequest(method, url, &payload)
+	req.Header.Set("Authorization", token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func expectStatus(t *testing.T, response *httptest.ResponseRecorder, code int) {
+	t.Helper()
+	if response.Code != code {
+		t.Fatalf("status %d, want %d: %s", response.Code, code, response.Body)
+	}
+}
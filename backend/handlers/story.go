@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	storyLifetime  = 24 * time.Hour
+	maxStoryLength = 500
+	// storyMediaDir is where story images are stored. It's outside the public static files, ReadStoryMedia serves
+	// the images to the story's audience only.
+	storyMediaDir = "media/stories"
+)
+
+func CreateStory(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	var story structs.Story
+	if err := helpers.DecodeJSONBody(r, &story); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	story.Content = strings.TrimSpace(story.Content)
+	if story.Content == "" && story.MediaData == "" {
+		http.Error(w, "Please provide a text or an image", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(story.Content) > maxStoryLength {
+		http.Error(w, "The story is too long", http.StatusBadRequest)
+		return
+	}
+	privacy, err := normalizeStoryPrivacy(story.Privacy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var media string
+	if story.MediaData != "" {
+		media, err = helpers.SaveImage(story.MediaData, storyMediaDir)
+		if errors.Is(err, helpers.ErrInvalidImage) || errors.Is(err, helpers.ErrImageTooLarge) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	now := time.Now()
+	storyId, err := database.InsertStory(structs.Story{
+		UserId:    userId,
+		Content:   story.Content,
+		Media:     media,
+		Privacy:   privacy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(storyLifetime),
+	})
+	if err != nil {
+		removeStoryMedia(media)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	saved, err := database.GetStoryById(storyId)
+	if err != nil || saved == nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	saved.Seen = true
+	setStoryMediaURL(saved)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// ReadStories lists the active stories of everyone the user is allowed to see, their own first
+func ReadStories(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	stories, err := database.ReadStories(userId, 0)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for i := range stories {
+		setStoryMediaURL(&stories[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stories)
+}
+
+// ReadUserStories lists the active stories of one user that the logged in user is allowed to see
+func ReadUserStories(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	vars := mux.Vars(r)
+	authorId, err := strconv.Atoi(vars["id"])
+	if err != nil || authorId <= 0 {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	stories, err := database.ReadStories(userId, authorId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for i := range stories {
+		setStoryMediaURL(&stories[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stories)
+}
+
+// MarkStorySeen records that the user viewed the story, the author's own views aren't counted
+func MarkStorySeen(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	story, ok := getVisibleStory(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if story.UserId != userId {
+		if err := database.MarkStorySeen(story.Id, userId); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	helpers.ReturnMessageJSON(w, "Story seen", http.StatusOK, "success")
+}
+
+// ReadStoryViewers lists who has seen the story, only its author can see the list
+func ReadStoryViewers(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	story, ok := getOwnStory(w, r, userId)
+	if !ok {
+		return
+	}
+
+	limit, offset := helpers.GetPagination(r)
+	viewers, err := database.ReadStoryViewers(story.Id, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(viewers)
+}
+
+func DeleteStory(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	story, ok := getOwnStory(w, r, userId)
+	if !ok {
+		return
+	}
+
+	if err := database.DeleteStory(story.Id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	removeStoryMedia(story.Media)
+
+	helpers.ReturnMessageJSON(w, "Story deleted", http.StatusOK, "success")
+}
+
+// ReadStoryMedia serves the image of a story the user is allowed to see
+func ReadStoryMedia(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := authenticateMediaRequest(w, r)
+	if !isAuthenticated {
+		return
+	}
+
+	story, ok := getVisibleStory(w, r, userId)
+	if !ok {
+		return
+	}
+	if story.Media == "" {
+		http.Error(w, "Story has no image", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeFile(w, r, filepath.Join(storyMediaDir, filepath.Base(story.Media)))
+}
+
+// authenticateMediaRequest reads the session token from the header, or from ?authorization= in the URL for
+// <img> tags, which can't send headers
+func authenticateMediaRequest(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Header.Get("Authorization") == "" {
+		return helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromURL)
+	}
+	return helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+}
+
+// RunStoryCleanup deletes expired stories and their images on every tick, it's started from main in its own goroutine
+func RunStoryCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleteExpiredStories(time.Now())
+	}
+}
+
+func deleteExpiredStories(now time.Time) {
+	media, err := database.DeleteExpiredStories(now)
+	if err != nil {
+		log.Println("Error deleting expired stories:", err)
+		return
+	}
+	for _, fileName := range media {
+		removeStoryMedia(fileName)
+	}
+}
+
+// normalizeStoryPrivacy checks the audience of a story, which works like the privacy of posts:
+// "public" (the default), "private" for followers only, or a comma separated list of user ids
+func normalizeStoryPrivacy(privacy string) (string, error) {
+	privacy = strings.TrimSpace(privacy)
+	if privacy == "" {
+		return "public", nil
+	}
+	if privacy == "public" || privacy == "private" {
+		return privacy, nil
+	}
+
+	ids := strings.Split(privacy, ",")
+	for i, id := range ids {
+		userId, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil || userId <= 0 {
+			return "", errors.New("Privacy must be public, private or a list of user ids")
+		}
+		ids[i] = strconv.Itoa(userId)
+	}
+	return strings.Join(ids, ","), nil
+}
+
+func setStoryMediaURL(story *structs.Story) {
+	if story.Media != "" {
+		story.Media = "/story/" + strconv.Itoa(story.Id) + "/media"
+	}
+}
+
+func removeStoryMedia(fileName string) {
+	if fileName == "" {
+		return
+	}
+	if err := helpers.RemoveImage(storyMediaDir, fileName); err != nil {
+		log.Println("Error removing story media:", err)
+	}
+}
+
+// getVisibleStory reads the story from the URL, stories the user can't see are reported as not found
+func getVisibleStory(w http.ResponseWriter, r *http.Request, userId int) (*structs.Story, bool) {
+	vars := mux.Vars(r)
+	storyId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	visible, err := database.IsStoryVisibleToUser(storyId, userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if !visible {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	story, err := database.GetStoryById(storyId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if story == nil {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return nil, false
+	}
+	return story, true
+}
+
+func getOwnStory(w http.ResponseWriter, r *http.Request, userId int) (*structs.Story, bool) {
+	vars := mux.Vars(r)
+	storyId, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid story ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	story, err := database.GetStoryById(storyId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if story == nil || story.UserId != userId {
+		http.Error(w, "Story not found", http.StatusNotFound)
+		return nil, false
+	}
+	return story, true
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"social-network/database"
+	"social-network/structs"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestReadStoryMediaAcceptsTokenFromURL(t *testing.T) {
+	openTestDB(t)
+	storyId, err := database.InsertStory(structs.Story{UserId: 1, Content: "text only", Privacy: "public", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readMedia := func(query string) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.HandleFunc("/story/{id}/media", ReadStoryMedia).Methods("GET")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", "/story/"+strconv.Itoa(storyId)+"/media"+query, nil))
+		return recorder
+	}
+
+	if response := readMedia(""); response.Code != http.StatusUnauthorized {
+		t.Fatalf("no token: status %d, want %d", response.Code, http.StatusUnauthorized)
+	}
+	// an <img> tag passes the token in the URL, the story is found and only lacks an image
+	response := readMedia("?authorization=" + loginTestUser(t, 2))
+	if response.Code != http.StatusNotFound || !strings.Contains(response.Body.String(), "Story has no image") {
+		t.Fatalf("token in the URL: status %d: %s", response.Code, response.Body)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"social-network/database"
+	"social-network/helpers"
+	"social-network/structs"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func ReadTagPosts(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	tag := helpers.NormalizeTag(mux.Vars(r)["tag"])
+	if tag == "" {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+	limit, offset := helpers.GetPagination(r)
+
+	posts, err := database.ReadPostsByTag(tag, userId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := attachPostDetails(posts, userId); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	groupPosts, err := database.ReadGroupPostsByTag(tag, userId, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	renderGroupPostsContent(groupPosts)
+
+	isFollowing, err := database.IsFollowingTag(userId, tag)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	tagPosts := structs.TagPosts{
+		Tag:         tag,
+		IsFollowing: isFollowing,
+		Posts:       posts,
+		GroupPosts:  groupPosts,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tagPosts)
+}
+
+func ReadTrendingTags(w http.ResponseWriter, r *http.Request) {
+	_, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	// sliding window in hours, one day by default and one week at most
+	hours, err := strconv.Atoi(r.URL.Query().Get("hours"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	if hours > 168 {
+		hours = 168
+	}
+	limit, _ := helpers.GetPagination(r)
+
+	tags, err := database.ReadTrendingTags(hours, limit)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func FollowTagHandler(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	tag := helpers.NormalizeTag(mux.Vars(r)["tag"])
+	if tag == "" {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	if err := database.FollowTag(userId, tag); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "You are now following #"+tag, http.StatusOK, "success")
+}
+
+func UnfollowTagHandler(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	tag := helpers.NormalizeTag(mux.Vars(r)["tag"])
+	if tag == "" {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	if err := database.UnfollowTag(userId, tag); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	helpers.ReturnMessageJSON(w, "You unfollowed #"+tag, http.StatusOK, "success")
+}
+
+func FollowedTagsHandler(w http.ResponseWriter, r *http.Request) {
+	userId, isAuthenticated := helpers.AuthenticateUserAndGetId(w, r, structs.TokenFromHeader)
+	if !isAuthenticated {
+		return
+	}
+
+	tags, err := database.GetFollowedTags(userId)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// websocketClient is the open connection of a user. A connection supports only one writer at a time and
+// messages are sent from many handlers at once, so every write goes through send.
+type websocketClient struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *websocketClient) send(message []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// websocketClients keeps the connection of every online user of one websocket endpoint
+type websocketClients struct {
+	mu      sync.RWMutex
+	clients map[int]*websocketClient
+}
+
+func newWebsocketClients() *websocketClients {
+	return &websocketClients{clients: make(map[int]*websocketClient)}
+}
+
+// add registers the connection of the user and returns the one it replaced, if any
+func (c *websocketClients) add(userId int, conn *websocket.Conn) (client, replaced *websocketClient) {
+	client = &websocketClient{conn: conn}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	replaced = c.clients[userId]
+	c.clients[userId] = client
+	return client, replaced
+}
+
+// remove unregisters the client unless the user has connected again since
+func (c *websocketClients) remove(userId int, client *websocketClient) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clients[userId] == client {
+		delete(c.clients, userId)
+	}
+}
+
+func (c *websocketClients) get(userId int) *websocketClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clients[userId]
+}
+
+func (c *websocketClients) userIds() []int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	userIds := make([]int, 0, len(c.clients))
+	for userId := range c.clients {
+		userIds = append(userIds, userId)
+	}
+	return userIds
+}
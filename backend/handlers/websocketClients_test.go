@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialTestWebsocket connects to a server that registers the connection for user 1 and returns the client end once it is registered
+func dialTestWebsocket(t *testing.T, clients *websocketClients) *websocket.Conn {
+	t.Helper()
+	registered := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		clients.add(1, conn)
+		close(registered)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	<-registered
+	return conn
+}
+
+func TestWebsocketClientConcurrentSends(t *testing.T) {
+	clients := newWebsocketClients()
+	conn := dialTestWebsocket(t, clients)
+
+	const senders, messages = 10, 50
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < messages; j++ {
+				if err := clients.get(1).send([]byte("update")); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < senders*messages; i++ {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(message) != "update" {
+			t.Fatalf("received %q", message)
+		}
+	}
+	wg.Wait()
+}
+
+func TestWebsocketClientsRemoveKeepsNewerConnection(t *testing.T) {
+	clients := newWebsocketClients()
+	old, _ := clients.add(1, nil)
+	current, replaced := clients.add(1, nil)
+	if replaced != old {
+		t.Fatal("the replaced connection wasn't returned")
+	}
+
+	clients.remove(1, old)
+	if clients.get(1) != current {
+		t.Fatal("closing the old connection unregistered the new one")
+	}
+	clients.remove(1, current)
+	if clients.get(1) != nil {
+		t.Fatal("the connection is still registered")
+	}
+}
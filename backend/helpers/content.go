@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// a hashtag starts at the beginning of the text or after whitespace, so anchors in urls are not picked up
+var hashtagRegex = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_]{1,50})`)
+
+// ExtractHashtags returns the unique lowercased tags found in the content, without the leading '#'
+func ExtractHashtags(content string) []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range hashtagRegex.FindAllStringSubmatch(content, -1) {
+		tag := strings.ToLower(match[1])
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeTag turns user input like "#GoLang" into the stored form "golang"
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// a mention is either a nickname or a numeric user id, e.g. "@chatterbox" or "@12"
+var mentionRegex = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_.-]{1,50})`)
+
+// ExtractMentions returns the unique lowercased nicknames or user ids mentioned in the content, without the leading '@'
+func ExtractMentions(content string) []string {
+	mentions := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		mention := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if mention == "" || seen[mention] {
+			continue
+		}
+		seen[mention] = true
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+var urlRegex = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// ExtractFirstURL returns the first http(s) link in the content without trailing punctuation, or "" if there is none
+func ExtractFirstURL(content string) string {
+	return strings.TrimRight(urlRegex.FindString(content), ".,;:!?)]}")
+}
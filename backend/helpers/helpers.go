@@ -79,6 +79,24 @@ func DifBetweenLoggedInUserAndOtherUser(r *http.Request, w http.ResponseWriter)
 	return userId, false
 }
 
+// GetPagination reads the "page" and "limit" query parameters and returns the limit and offset for SQL queries
+func GetPagination(r *http.Request) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	return limit, (page - 1) * limit
+}
+
 func ReturnMessageJSON(w http.ResponseWriter, message string, httpCode int, status string) {
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(httpCode)
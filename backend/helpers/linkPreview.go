@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"social-network/structs"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// LinkPreviewFetcher loads the preview metadata of a web page. Tests can swap in their own implementation
+// or point an HTTPLinkPreviewFetcher at a local server.
+type LinkPreviewFetcher interface {
+	Fetch(pageUrl string) (*structs.LinkPreview, error)
+}
+
+// HTTPLinkPreviewFetcher fetches the page and reads its OpenGraph and Twitter card tags
+type HTTPLinkPreviewFetcher struct {
+	Client   *http.Client
+	MaxBytes int64
+}
+
+var errBlockedAddress = errors.New("link preview address is not public")
+
+// NewLinkPreviewFetcher returns a fetcher that only connects to public addresses, with short timeouts
+// and a cap on how much of the page is read
+func NewLinkPreviewFetcher() *HTTPLinkPreviewFetcher {
+	dialer := &net.Dialer{
+		Timeout: 3 * time.Second,
+		// the check runs on the resolved address of every connection, redirects included,
+		// so a hostname pointing at an internal address is blocked as well
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !IsPublicIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		// no proxy, the address check has to see the real destination
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: 3 * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   5 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("too many redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.New("unsupported redirect scheme")
+			}
+			return nil
+		},
+	}
+
+	return &HTTPLinkPreviewFetcher{Client: client, MaxBytes: 512 * 1024}
+}
+
+// the carrier-grade NAT range isn't covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP reports whether the address is routable on the internet
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil && (sharedAddressSpace.Contains(ip4) || ip4[0] == 0 || ip4.Equal(net.IPv4bcast)) {
+		return false
+	}
+	return true
+}
+
+func (f *HTTPLinkPreviewFetcher) Fetch(pageUrl string) (*structs.LinkPreview, error) {
+	parsedUrl, err := url.Parse(pageUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return nil, fmt.Errorf("invalid link preview url: %s", pageUrl)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, parsedUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "social-network-link-preview/1.0")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching link preview: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link preview page returned %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, errors.New("link preview page isn't html")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error reading link preview page: %v", err)
+	}
+
+	preview := ParseLinkPreview(string(body), resp.Request.URL)
+	preview.Url = pageUrl
+	return preview, nil
+}
+
+var (
+	metaTagRegex   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrRegex  = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	titleTagRegex  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	whitespaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// ParseLinkPreview reads the OpenGraph tags of the page, falling back to Twitter card tags and the <title>.
+// Relative image urls are resolved against the page url.
+func ParseLinkPreview(page string, pageUrl *url.URL) *structs.LinkPreview {
+	tags := make(map[string]string)
+	for _, tag := range metaTagRegex.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, attr := range metaAttrRegex.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(attr[1])] = attr[2] + attr[3]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if key != "" && tags[key] == "" {
+			tags[key] = attrs["content"]
+		}
+	}
+
+	firstOf := func(keys ...string) string {
+		for _, key := range keys {
+			if value := cleanPreviewText(tags[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := &structs.LinkPreview{
+		Title:       firstOf("og:title", "twitter:title"),
+		Description: firstOf("og:description", "twitter:description", "description"),
+		SiteName:    firstOf("og:site_name"),
+	}
+	if preview.Title == "" {
+		if match := titleTagRegex.FindStringSubmatch(page); match != nil {
+			preview.Title = cleanPreviewText(match[1])
+		}
+	}
+	preview.Title = truncateText(preview.Title, 200)
+	preview.Description = truncateText(preview.Description, 500)
+	preview.SiteName = truncateText(preview.SiteName, 100)
+
+	if image := firstOf("og:image", "og:image:url", "twitter:image", "twitter:image:src"); image != "" {
+		if imageUrl, err := pageUrl.Parse(image); err == nil && (imageUrl.Scheme == "http" || imageUrl.Scheme == "https") {
+			preview.Image = imageUrl.String()
+		}
+	}
+	return preview
+}
+
+func cleanPreviewText(text string) string {
+	return strings.TrimSpace(whitespaceRuns.ReplaceAllString(html.UnescapeString(text), " "))
+}
+
+func truncateText(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	return string([]rune(text)[:maxRunes]) + "…"
+}
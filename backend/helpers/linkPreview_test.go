@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func servePage(page string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}))
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	if _, err := NewLinkPreviewFetcher().Fetch(server.URL); err == nil {
+		t.Fatal("fetching a loopback address succeeded")
+	}
+	if hit {
+		t.Fatal("the loopback server was reached")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for address, public := range map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+	} {
+		if IsPublicIP(net.ParseIP(address)) != public {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", address, !public, public)
+		}
+	}
+}
+
+func TestFetchReadsUpToMaxBytes(t *testing.T) {
+	server := servePage(`<html><head><meta property="og:title" content="Early"></head><body>` +
+		strings.Repeat("x", 2048) + `<meta property="og:description" content="Late"></body></html>`)
+	defer server.Close()
+
+	fetcher := &HTTPLinkPreviewFetcher{Client: server.Client(), MaxBytes: 1024}
+	preview, err := fetcher.Fetch(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Title != "Early" {
+		t.Fatalf("title = %q, want %q", preview.Title, "Early")
+	}
+	if preview.Description != "" {
+		t.Fatalf("description past the size cap was read: %q", preview.Description)
+	}
+}
+
+func TestFetchTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := server.Client()
+	client.Timeout = 100 * time.Millisecond
+	fetcher := &HTTPLinkPreviewFetcher{Client: client, MaxBytes: 1024}
+
+	start := time.Now()
+	if _, err := fetcher.Fetch(server.URL); err == nil {
+		t.Fatal("fetching a page that never answers succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("fetch gave up after %v", elapsed)
+	}
+}
@@ -0,0 +1,210 @@
+package helpers
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenderMarkdown turns the supported Markdown subset into HTML: paragraphs, line breaks, **bold**,
+// *italics* or _italics_, `code`, ``` code blocks, [links](https://...), bare urls and - or 1. lists.
+// Everything else is escaped, so the only tags in the output are the ones generated here
+// (p, br, strong, em, code, pre, a, ul, ol, li) and links only ever point at http(s) or mailto urls.
+func RenderMarkdown(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		out.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				out.WriteString("<br>")
+			}
+			out.WriteString(renderInline(line))
+		}
+		out.WriteString("</p>")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+
+		case listItemRegex.MatchString(line), orderedItemRegex.MatchString(line):
+			flushParagraph()
+			itemRegex, tag := listItemRegex, "ul"
+			if orderedItemRegex.MatchString(line) {
+				itemRegex, tag = orderedItemRegex, "ol"
+			}
+			out.WriteString("<" + tag + ">")
+			for ; i < len(lines) && itemRegex.MatchString(lines[i]); i++ {
+				out.WriteString("<li>" + renderInline(itemRegex.FindStringSubmatch(lines[i])[1]) + "</li>")
+			}
+			i--
+			out.WriteString("</" + tag + ">")
+
+		case trimmed == "":
+			flushParagraph()
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	return out.String()
+}
+
+var (
+	listItemRegex    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedItemRegex = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	bareURLRegex     = regexp.MustCompile(`^https?://[^\s<>"']+`)
+)
+
+// renderInline escapes the text and converts the inline markup, links can't be nested inside link text
+func renderInline(text string) string {
+	return renderSpans(text, true)
+}
+
+func renderSpans(text string, allowLinks bool) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				out.WriteString("<code>" + html.EscapeString(rest[1:1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**"):
+			if end := strings.Index(rest[2:], "**"); end > 0 {
+				out.WriteString("<strong>" + renderSpans(rest[2:2+end], allowLinks) + "</strong>")
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			// underscores inside words, like snake_case names, aren't emphasis
+			if rest[0] == '*' || !isWordByteBefore(text, i) {
+				if end := closingDelimiter(rest, rest[0]); end > 0 {
+					out.WriteString("<em>" + renderSpans(rest[1:end], allowLinks) + "</em>")
+					i += end + 1
+					continue
+				}
+			}
+
+		case rest[0] == '[' && allowLinks:
+			if label, target, length, ok := parseLink(rest); ok {
+				if href, ok := safeLinkURL(target); ok {
+					out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + renderSpans(label, false) + "</a>")
+					i += length
+					continue
+				}
+			}
+
+		case rest[0] == 'h' && allowLinks && !isWordByteBefore(text, i):
+			if match := bareURLRegex.FindString(rest); match != "" {
+				link := strings.TrimRight(match, ".,;:!?)]}*_")
+				if href, ok := safeLinkURL(link); ok {
+					out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + html.EscapeString(link) + "</a>")
+					i += len(link)
+					continue
+				}
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		out.WriteString(html.EscapeString(rest[:size]))
+		i += size
+	}
+	return out.String()
+}
+
+// closingDelimiter finds the single * or _ that closes the emphasis opened at the start of text
+func closingDelimiter(text string, delimiter byte) int {
+	for end := 1; end < len(text); end++ {
+		if text[end] != delimiter {
+			continue
+		}
+		if end == 1 || text[end-1] == ' ' {
+			return -1
+		}
+		if delimiter == '*' && end+1 < len(text) && text[end+1] == '*' {
+			end++
+			continue
+		}
+		if delimiter == '_' && end+1 < len(text) && isWordByte(text[end+1]) {
+			continue
+		}
+		return end
+	}
+	return -1
+}
+
+// parseLink reads "[label](target)" from the start of text
+func parseLink(text string) (label, target string, length int, ok bool) {
+	labelEnd := strings.Index(text, "](")
+	if labelEnd < 1 || strings.ContainsAny(text[1:labelEnd], "[]") {
+		return "", "", 0, false
+	}
+	targetEnd := strings.IndexByte(text[labelEnd+2:], ')')
+	if targetEnd < 1 {
+		return "", "", 0, false
+	}
+	target = strings.TrimSpace(text[labelEnd+2 : labelEnd+2+targetEnd])
+	if strings.ContainsAny(target, " \t") {
+		return "", "", 0, false
+	}
+	return text[1:labelEnd], target, labelEnd + 3 + targetEnd, true
+}
+
+// safeLinkURL only lets absolute http(s) and mailto links through, so javascript: and data: urls are never rendered as links
+func safeLinkURL(link string) (string, bool) {
+	parsedUrl, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(parsedUrl.Scheme) {
+	case "http", "https":
+		if parsedUrl.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if parsedUrl.Opaque == "" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return parsedUrl.String(), true
+}
+
+func isWordByteBefore(text string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= 0x80 || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxImageSize is the largest decoded image SaveImage accepts, in bytes
+const MaxImageSize = 5 << 20
+
+var (
+	ErrInvalidImage  = errors.New("the image must be a png, jpeg, gif or webp data url")
+	ErrImageTooLarge = errors.New("the image is too large")
+)
+
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// SaveImage decodes a base64 data url ("data:image/png;base64,...") and writes it to dir under a random name,
+// which it returns. The type is sniffed from the decoded bytes, the one claimed by the data url is ignored.
+func SaveImage(dataURL, dir string) (string, error) {
+	header, encoded, found := strings.Cut(dataURL, ",")
+	if !found || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return "", ErrInvalidImage
+	}
+	if base64.StdEncoding.DecodedLen(len(encoded)) > MaxImageSize+2 {
+		return "", ErrImageTooLarge
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidImage
+	}
+	if len(data) > MaxImageSize {
+		return "", ErrImageTooLarge
+	}
+
+	extension, ok := imageExtensions[http.DetectContentType(data)]
+	if !ok {
+		return "", ErrInvalidImage
+	}
+
+	name, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	fileName := name.String() + extension
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, fileName), data, 0644); err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// RemoveImage deletes an image saved by SaveImage, an image that is already gone isn't an error
+func RemoveImage(dir, fileName string) error {
+	err := os.Remove(filepath.Join(dir, filepath.Base(fileName)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
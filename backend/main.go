@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"social-network/database"
 	"social-network/handlers"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	_ "github.com/mattn/go-sqlite3"
@@ -15,17 +18,45 @@ func main() {
 
 	database.InitDB()
 
+	if depth, err := strconv.Atoi(os.Getenv("COMMENT_MAX_DEPTH")); err == nil && depth >= 0 {
+		handlers.MaxCommentDepth = depth
+	}
+	if days, err := strconv.Atoi(os.Getenv("JOIN_REQUEST_COOLDOWN_DAYS")); err == nil && days >= 0 {
+		handlers.JoinRequestCooldown = time.Duration(days) * 24 * time.Hour
+	}
+	if days, err := strconv.Atoi(os.Getenv("JOIN_REQUEST_EXPIRY_DAYS")); err == nil && days > 0 {
+		handlers.JoinRequestLifetime = time.Duration(days) * 24 * time.Hour
+	}
+
+	go handlers.RunScheduledPostPublisher(30 * time.Second)
+	go handlers.RunStoryCleanup(5 * time.Minute)
+	go handlers.RunJoinRequestExpiry(time.Hour)
+
 	r := mux.NewRouter()
 
 	r.HandleFunc("/register", handlers.RegisterHandler).Methods("POST")
 	r.HandleFunc("/login", handlers.LoginHandler).Methods("POST")
 	r.HandleFunc("/user/info", handlers.ReadUserInfo).Methods("GET")
 	r.HandleFunc("/post/{id}/comment/create", handlers.CreateComment).Methods("POST")
+	r.HandleFunc("/post/{id}/comment/get", handlers.ReadComments).Methods("GET")
+	r.HandleFunc("/post/{id}/comment/{commentId}/replies", handlers.ReadCommentReplies).Methods("GET")
+	r.HandleFunc("/post/{id}/comment/{commentId}", handlers.EditComment).Methods("PATCH")
+	r.HandleFunc("/post/{id}/comment/{commentId}/reaction", handlers.ReactToComment).Methods("POST")
+	r.HandleFunc("/post/{id}/comment/{commentId}/reaction", handlers.RemoveCommentReaction).Methods("DELETE")
 	r.HandleFunc("/profile/me", handlers.LoggedInUserProfileHandler).Methods("GET")
 	r.HandleFunc("/profile/{id}", handlers.OtherUserProfileHandler).Methods("GET")
 	r.HandleFunc("/logout", handlers.LogoutHandler)
 	r.HandleFunc("/post/create", handlers.CreatePost).Methods("POST")
 	r.HandleFunc("/post/get", handlers.ReadPosts).Methods("GET")
+	r.HandleFunc("/post/{id}/repost", handlers.Repost).Methods("POST")
+	r.HandleFunc("/post/{id}", handlers.DeletePostHandler).Methods("DELETE")
+	r.HandleFunc("/post/scheduled/get", handlers.ReadScheduledPosts).Methods("GET")
+	r.HandleFunc("/post/{id}/edit", handlers.EditScheduledPost).Methods("PATCH")
+	r.HandleFunc("/post/{id}/schedule/cancel", handlers.CancelScheduledPost).Methods("POST")
+	r.HandleFunc("/post/{id}/poll", handlers.ReadPoll).Methods("GET")
+	r.HandleFunc("/post/{id}/poll/vote", handlers.VotePoll).Methods("POST")
+	r.HandleFunc("/post/{id}/pin", handlers.PinPost).Methods("POST")
+	r.HandleFunc("/post/{id}/pin", handlers.UnpinPost).Methods("DELETE")
 	r.HandleFunc("/message-websocket", handlers.MessageWebSocketHandler)
 	r.HandleFunc("/chat-display", handlers.ChatDisplayHandler).Methods("GET")
 	r.HandleFunc("/message-display", handlers.MessageHandler).Methods("GET")
@@ -40,10 +71,25 @@ func main() {
 	r.HandleFunc("/group/{id}/get", handlers.GroupHandler).Methods("GET")
 	r.HandleFunc("/group/{id}/post/create", handlers.CreateGroupPost).Methods("POST")
 	r.HandleFunc("/group/{id}/post/get", handlers.ReadGroupPosts).Methods("GET")
+	r.HandleFunc("/group/{id}/post/pending", handlers.ReadPendingGroupPosts).Methods("GET")
 	r.HandleFunc("/group/{groupId}/post/{postId}/comment/create", handlers.CreateCommentInGroup).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/get", handlers.ReadGroupComments).Methods("GET")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/{commentId}/replies", handlers.ReadGroupCommentReplies).Methods("GET")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/{commentId}", handlers.EditGroupComment).Methods("PATCH")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/{commentId}/reaction", handlers.ReactToGroupComment).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/{commentId}/reaction", handlers.RemoveGroupCommentReaction).Methods("DELETE")
+	r.HandleFunc("/group/{groupId}/post/{postId}/edit", handlers.EditScheduledGroupPost).Methods("PATCH")
+	r.HandleFunc("/group/{groupId}/post/{postId}/schedule/cancel", handlers.CancelScheduledGroupPost).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/approve", handlers.ApproveGroupPost).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/reject", handlers.RejectGroupPost).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/pin", handlers.PinGroupPost).Methods("POST")
+	r.HandleFunc("/group/{groupId}/post/{postId}/pin", handlers.UnpinGroupPost).Methods("DELETE")
+	r.HandleFunc("/group/{groupId}/post/{postId}", handlers.DeleteGroupPostHandler).Methods("DELETE")
+	r.HandleFunc("/group/{groupId}/post/{postId}/comment/{commentId}", handlers.DeleteGroupComment).Methods("DELETE")
 	r.HandleFunc("/group/{id}/event/create", handlers.CreateGroupEvent).Methods("POST")
 	r.HandleFunc("/group/{id}/event/get", handlers.ReadGroupEvents).Methods("GET")
 	r.HandleFunc("/group/{id}/event/choice", handlers.SelectEventOption).Methods("POST")
+	r.HandleFunc("/group/{id}/event/{eventId}", handlers.EditGroupEvent).Methods("PATCH")
 	r.HandleFunc("/group/{id}/invite", handlers.InviteUsers).Methods("POST")
 	r.HandleFunc("/group/{id}/invite/accept", handlers.AcceptInvitation).Methods("POST")
 	r.HandleFunc("/group/{id}/invite/decline", handlers.DeclineInvitation).Methods("POST")
@@ -51,6 +97,33 @@ func main() {
 	r.HandleFunc("/group/{id}/leave", handlers.LeaveGroup).Methods("POST")
 	r.HandleFunc("/group/{id}/join/accept", handlers.AcceptJoinRequest).Methods("POST")
 	r.HandleFunc("/group/{id}/join/decline", handlers.DeclineJoinRequest).Methods("POST")
+	r.HandleFunc("/group/{id}/join/withdraw", handlers.WithdrawJoinRequest).Methods("POST")
+	r.HandleFunc("/group/{id}/join/requests", handlers.ReadPendingJoinRequests).Methods("GET")
+	r.HandleFunc("/group/{id}/questions", handlers.ReadGroupQuestions).Methods("GET")
+	r.HandleFunc("/group/{id}/questions", handlers.UpdateGroupQuestions).Methods("PUT")
+	r.HandleFunc("/group/{id}/members", handlers.ReadGroupMembers).Methods("GET")
+	r.HandleFunc("/group/{id}/member/{userId}/role", handlers.ChangeGroupMemberRole).Methods("PATCH")
+	r.HandleFunc("/group/{id}/member/{userId}/remove", handlers.RemoveGroupMember).Methods("POST")
+	r.HandleFunc("/group/{id}/member/{userId}/ban", handlers.BanGroupMember).Methods("POST")
+	r.HandleFunc("/group/{id}/member/{userId}/ban", handlers.UnbanGroupMember).Methods("DELETE")
+	r.HandleFunc("/group/{id}/bans", handlers.ReadGroupBans).Methods("GET")
+	r.HandleFunc("/group/{id}/audit-log", handlers.ReadGroupAuditLog).Methods("GET")
+	r.HandleFunc("/group/{id}/invite-link", handlers.CreateGroupInviteLink).Methods("POST")
+	r.HandleFunc("/group/{id}/invite-links", handlers.ReadGroupInviteLinks).Methods("GET")
+	r.HandleFunc("/group/{id}/invite-link/{linkId}", handlers.RevokeGroupInviteLink).Methods("DELETE")
+	r.HandleFunc("/group/{id}/invite-link/{linkId}/uses", handlers.ReadGroupInviteLinkUses).Methods("GET")
+	r.HandleFunc("/invite/{token}", handlers.PreviewInviteLink).Methods("GET")
+	r.HandleFunc("/invite/{token}/join", handlers.JoinWithInviteLink).Methods("POST")
+	r.HandleFunc("/invite/{token}/cover", handlers.ReadInviteLinkCover).Methods("GET")
+	r.HandleFunc("/group/{id}/transfer", handlers.TransferGroupOwnership).Methods("POST")
+	r.HandleFunc("/group/{id}/transfer", handlers.CancelGroupOwnershipTransfer).Methods("DELETE")
+	r.HandleFunc("/group/{id}/transfer/accept", handlers.AcceptGroupOwnership).Methods("POST")
+	r.HandleFunc("/group/{id}/transfer/decline", handlers.DeclineGroupOwnership).Methods("POST")
+	r.HandleFunc("/group/{id}/archive", handlers.ArchiveGroup).Methods("POST")
+	r.HandleFunc("/group/{id}/unarchive", handlers.UnarchiveGroup).Methods("POST")
+	r.HandleFunc("/group/{id}", handlers.UpdateGroup).Methods("PATCH")
+	r.HandleFunc("/group/{id}/cover", handlers.ReadGroupCover).Methods("GET")
+	r.HandleFunc("/group/{id}", handlers.DeleteGroup).Methods("DELETE")
 
 	//FOLLOW
 	r.HandleFunc("/profile/me/requests", handlers.FetchFollowRequestHandler).Methods("GET")
@@ -64,6 +137,49 @@ func main() {
 	r.HandleFunc("/profile/privacy", handlers.UpdateProfilePrivacyHandler).Methods("PATCH")
 	r.HandleFunc("/follow/unfollow/{userId}", handlers.UnfollowRequestHandler).Methods("DELETE")
 
+	//TAGS
+	r.HandleFunc("/tags/trending", handlers.ReadTrendingTags).Methods("GET")
+	r.HandleFunc("/tags/following", handlers.FollowedTagsHandler).Methods("GET")
+	r.HandleFunc("/tags/{tag}/posts", handlers.ReadTagPosts).Methods("GET")
+	r.HandleFunc("/tags/{tag}/follow", handlers.FollowTagHandler).Methods("POST")
+	r.HandleFunc("/tags/{tag}/unfollow", handlers.UnfollowTagHandler).Methods("DELETE")
+
+	//BOOKMARKS
+	r.HandleFunc("/bookmark/create", handlers.AddBookmark).Methods("POST")
+	r.HandleFunc("/bookmark/{id}", handlers.RemoveBookmark).Methods("DELETE")
+	r.HandleFunc("/bookmark/collection/create", handlers.CreateBookmarkCollection).Methods("POST")
+	r.HandleFunc("/bookmark/collection/get", handlers.ReadBookmarkCollections).Methods("GET")
+	r.HandleFunc("/bookmark/collection/{id}/get", handlers.ReadBookmarkCollection).Methods("GET")
+	r.HandleFunc("/bookmark/collection/{id}/order", handlers.ReorderBookmarks).Methods("PATCH")
+	r.HandleFunc("/bookmark/collection/{id}", handlers.DeleteBookmarkCollection).Methods("DELETE")
+
+	//STORIES
+	r.HandleFunc("/story/create", handlers.CreateStory).Methods("POST")
+	r.HandleFunc("/story/get", handlers.ReadStories).Methods("GET")
+	r.HandleFunc("/story/user/{id}/get", handlers.ReadUserStories).Methods("GET")
+	r.HandleFunc("/story/{id}/seen", handlers.MarkStorySeen).Methods("POST")
+	r.HandleFunc("/story/{id}/viewers", handlers.ReadStoryViewers).Methods("GET")
+	r.HandleFunc("/story/{id}/media", handlers.ReadStoryMedia).Methods("GET")
+	r.HandleFunc("/story/{id}", handlers.DeleteStory).Methods("DELETE")
+
+	//MODERATION
+	r.HandleFunc("/report/create", handlers.CreateReport).Methods("POST")
+	r.HandleFunc("/moderation/report/get", handlers.ReadReports).Methods("GET")
+	r.HandleFunc("/moderation/report/{id}/get", handlers.ReadReport).Methods("GET")
+	r.HandleFunc("/moderation/report/{id}/assign", handlers.AssignReport).Methods("POST")
+	r.HandleFunc("/moderation/report/{id}/resolve", handlers.ResolveReport).Methods("POST")
+
+	//ADMIN
+	r.HandleFunc("/admin/user/get", handlers.AdminReadUsers).Methods("GET")
+	r.HandleFunc("/admin/user/{id}/role", handlers.AdminSetUserRole).Methods("PATCH")
+	r.HandleFunc("/admin/user/{id}/suspend", handlers.AdminSuspendUser).Methods("POST")
+	r.HandleFunc("/admin/user/{id}/unsuspend", handlers.AdminUnsuspendUser).Methods("POST")
+	r.HandleFunc("/admin/user/{id}/logout", handlers.AdminLogoutUser).Methods("POST")
+	r.HandleFunc("/admin/content/{type}/{id}", handlers.AdminDeleteContent).Methods("DELETE")
+	r.HandleFunc("/admin/report/get", handlers.ReadReports).Methods("GET")
+	r.HandleFunc("/admin/stats", handlers.AdminReadStats).Methods("GET")
+	r.HandleFunc("/admin/audit/get", handlers.AdminReadAuditLog).Methods("GET")
+
 	fs := http.FileServer(http.Dir("static/images"))
 	r.PathPrefix("/static/images/").Handler(http.StripPrefix("/static/images/", fs))
 
@@ -10,17 +10,19 @@ const (
 )
 
 type User struct {
-	Id          int    `json:"id"`
-	FirstName   string `json:"firstName"`
-	LastName    string `json:"lastName"`
-	Email       string `json:"email,omitempty"`
-	Password    string `json:"password,omitempty"`
-	DateOfBirth string `json:"dateOfBirth,omitempty"`
-	Nickname    string `json:"nickname,omitempty"`
-	Avatar      string `json:"avatar,omitempty"`
-	AboutMe     string `json:"aboutMe,omitempty"`
-	IsPrivate   bool   `json:"isPrivate"`
-	UserGroups  []int  `json:"userGroups,omitempty"`
+	Id             int        `json:"id"`
+	FirstName      string     `json:"firstName"`
+	LastName       string     `json:"lastName"`
+	Email          string     `json:"email,omitempty"`
+	Password       string     `json:"password,omitempty"`
+	DateOfBirth    string     `json:"dateOfBirth,omitempty"`
+	Nickname       string     `json:"nickname,omitempty"`
+	Avatar         string     `json:"avatar,omitempty"`
+	AboutMe        string     `json:"aboutMe,omitempty"`
+	IsPrivate      bool       `json:"isPrivate"`
+	UserGroups     []int      `json:"userGroups,omitempty"`
+	Role           string     `json:"role,omitempty"` // "user", "moderator" or "admin"
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
 }
 
 type RegistrationRequest struct {
@@ -46,34 +48,59 @@ type LoginResponse struct {
 	Email      string    `json:"email"`
 	SessionId  string    `json:"sessionId"`
 	Expiration time.Time `json:"expiration"`
+	Role       string    `json:"role"`
 }
 
 type Post struct {
-	Id             int       `json:"id"`
-	UserId         int       `json:"userId"`
-	Privacy        string    `json:"privacy"`
-	Title          string    `json:"title"`
-	Content        string    `json:"content"`
-	Photo          string    `json:"photo,omitempty"`
-	ProfilePicture string    `json:"profilePicture"`
-	Comments       []Comment `json:"comments"`
+	Id              int          `json:"id"`
+	UserId          int          `json:"userId"`
+	Privacy         string       `json:"privacy"`
+	Title           string       `json:"title"`
+	Content         string       `json:"content"`
+	ContentHTML     string       `json:"contentHtml"` // rendered from the Markdown in Content, safe to insert as HTML
+	Photo           string       `json:"photo,omitempty"`
+	ProfilePicture  string       `json:"profilePicture"`
+	Comments        []Comment    `json:"comments"`
+	RepostOf        int          `json:"repostOf,omitempty"`
+	Original        *Post        `json:"original,omitempty"`
+	OriginalDeleted bool         `json:"originalDeleted,omitempty"`
+	RepostCount     int          `json:"repostCount"`
+	Status          string       `json:"status,omitempty"` // "published", "draft", "scheduled" or "hidden" by a moderator
+	PublishAt       *time.Time   `json:"publishAt,omitempty"`
+	Poll            *Poll        `json:"poll,omitempty"`
+	LinkPreview     *LinkPreview `json:"linkPreview,omitempty"`
+	Pinned          bool         `json:"pinned,omitempty"`
 }
 
 type Comment struct {
-	Id             int    `json:"id"`
-	PostId         int    `json:"postId"`
-	UserId         int    `json:"userId"`
-	CreatorName    string `json:"creatorName"`
-	ProfilePicture string `json:"profilePicture"`
-	Content        string `json:"content"`
-	Photo          string `json:"photo,omitempty"`
+	Id             int        `json:"id"`
+	PostId         int        `json:"postId"`
+	UserId         int        `json:"userId"`
+	CreatorName    string     `json:"creatorName"`
+	ProfilePicture string     `json:"profilePicture"`
+	Content        string     `json:"content"`
+	ContentHTML    string     `json:"contentHtml"`
+	Photo          string     `json:"photo,omitempty"`
+	ParentId       int        `json:"parentId,omitempty"`
+	ReplyCount     int        `json:"replyCount"`
+	Replies        []Comment  `json:"replies,omitempty"`
+	Reactions      int        `json:"reactions"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	EditedAt       *time.Time `json:"editedAt,omitempty"`
 }
 type Group struct {
-	Id          int    `json:"id"`
-	CreatorId   int    `json:"creatorId"`
-	Name        string `json:"groupName"`
-	Description string `json:"groupDescription"`
-	Members     []int  `json:"members"`
+	Id             int    `json:"id"`
+	CreatorId      int    `json:"creatorId"`
+	Name           string `json:"groupName"`
+	Description    string `json:"groupDescription"`
+	Cover          string `json:"cover,omitempty"`
+	Rules          string `json:"rules,omitempty"`
+	Members        []int  `json:"members"`
+	Role           string `json:"role,omitempty"` // the viewer's role in the group, empty if they aren't a member
+	Archived       bool   `json:"archived"`
+	Visibility     string `json:"visibility"`               // "public", "private" or "secret"
+	PendingOwnerId int    `json:"pendingOwnerId,omitempty"` // the member the owner offered the group to, until they answer
+	PostApproval   bool   `json:"postApproval"`             // new posts wait for a moderator's approval
 }
 
 type GroupPost struct {
@@ -81,31 +108,43 @@ type GroupPost struct {
 	UserId         int            `json:"userId"`
 	Title          string         `json:"title"`
 	Content        string         `json:"content"`
+	ContentHTML    string         `json:"contentHtml"`
 	Photo          string         `json:"photo,omitempty"`
 	ProfilePicture string         `json:"profilePicture"`
 	GroupId        int            `json:"groupId"`
 	Comments       []GroupComment `json:"comments"`
+	Status         string         `json:"status,omitempty"` // "published", "draft", "scheduled", "hidden" by a moderator, or "pending" and "rejected" in groups that approve posts
+	PublishAt      *time.Time     `json:"publishAt,omitempty"`
+	Pinned         bool           `json:"pinned,omitempty"` // pinned by the group owner as an announcement
 }
 
 type GroupComment struct {
-	Id             int    `json:"id"`
-	PostId         int    `json:"postId"`
-	GroupId        int    `json:"groupId"`
-	UserId         int    `json:"userId"`
-	CreatorName    string `json:"creatorName"`
-	ProfilePicture string `json:"profilePicture"`
-	Content        string `json:"content"`
-	Photo          string `json:"photo,omitempty"`
+	Id             int            `json:"id"`
+	PostId         int            `json:"postId"`
+	GroupId        int            `json:"groupId"`
+	UserId         int            `json:"userId"`
+	CreatorName    string         `json:"creatorName"`
+	ProfilePicture string         `json:"profilePicture"`
+	Content        string         `json:"content"`
+	ContentHTML    string         `json:"contentHtml"`
+	Photo          string         `json:"photo,omitempty"`
+	ParentId       int            `json:"parentId,omitempty"`
+	ReplyCount     int            `json:"replyCount"`
+	Replies        []GroupComment `json:"replies,omitempty"`
+	Reactions      int            `json:"reactions"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	EditedAt       *time.Time     `json:"editedAt,omitempty"`
 }
 
 type ChatMessage struct {
-	Id            int       `json:"id,omitempty"`
-	SenderId      int       `json:"senderId,omitempty"`
-	Content       string    `json:"content,omitempty"`
-	CreatedAt     time.Time `json:"createdAt,omitempty"`
-	PrivateChatId int       `json:"privateChatId,omitempty"`
-	GroupChatId   int       `json:"groupChatId,omitempty"`
-	Avatar        string    `json:"avatar"`
+	Id            int          `json:"id,omitempty"`
+	SenderId      int          `json:"senderId,omitempty"`
+	Content       string       `json:"content,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt,omitempty"`
+	PrivateChatId int          `json:"privateChatId,omitempty"`
+	GroupChatId   int          `json:"groupChatId,omitempty"`
+	Avatar        string       `json:"avatar"`
+	LinkPreview   *LinkPreview `json:"linkPreview,omitempty"`
 }
 
 type PrivateChat struct {
@@ -156,6 +195,13 @@ type Event struct {
 	ChosenOption string   `json:"chosenOption"`
 }
 
+// EventUpdate holds the fields of an event to change, fields left out keep their value
+type EventUpdate struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Time        *string `json:"time"`
+}
+
 type EventOption struct {
 	UserId  int    `json:"userId"`
 	EventId int    `json:"eventId"`
@@ -187,6 +233,15 @@ type JoinRequest struct {
 	GroupId     int `json:"groupId"`
 }
 
+type GroupJoinRequest struct {
+	UserId      int        `json:"userId"`
+	GroupId     int        `json:"groupId"`
+	Kind        string     `json:"kind"`   // "request" when the user asked to join, "invitation" when they were invited
+	Status      string     `json:"status"` // "pending", "accepted", "declined", "withdrawn" or "expired"
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+}
+
 type ErrorResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
@@ -196,3 +251,308 @@ type InviteUsers struct {
 	UsersId []int `json:"usersId"`
 	GroupId int   `json:"groupId"`
 }
+
+type TagPosts struct {
+	Tag         string      `json:"tag"`
+	IsFollowing bool        `json:"isFollowing"`
+	Posts       []Post      `json:"posts"`
+	GroupPosts  []GroupPost `json:"groupPosts"`
+}
+
+type TrendingTag struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type Mention struct {
+	Id            int    `json:"id"`
+	UserId        int    `json:"userId"`
+	SenderId      int    `json:"senderId"`
+	ContentType   string `json:"contentType"` // "post", "comment", "group_post", "group_comment", "private_message" or "group_message"
+	ContentId     int    `json:"contentId"`
+	PostId        int    `json:"postId,omitempty"`
+	GroupId       int    `json:"groupId,omitempty"`
+	PrivateChatId int    `json:"privateChatId,omitempty"`
+}
+
+type BookmarkCollection struct {
+	Id        int    `json:"id"`
+	UserId    int    `json:"userId"`
+	Name      string `json:"name"`
+	Bookmarks int    `json:"bookmarks"`
+}
+
+type Bookmark struct {
+	Id           int        `json:"id"`
+	UserId       int        `json:"userId"`
+	CollectionId int        `json:"collectionId"`
+	PostId       int        `json:"postId,omitempty"`
+	GroupPostId  int        `json:"groupPostId,omitempty"`
+	Position     int        `json:"position"`
+	Post         *Post      `json:"post,omitempty"`
+	GroupPost    *GroupPost `json:"groupPost,omitempty"`
+}
+
+type ReorderBookmarks struct {
+	BookmarkIds []int `json:"bookmarkIds"`
+}
+
+type ScheduledPosts struct {
+	Posts      []Post      `json:"posts"`
+	GroupPosts []GroupPost `json:"groupPosts"`
+}
+
+type Poll struct {
+	Id             int          `json:"id"`
+	PostId         int          `json:"postId"`
+	MultipleChoice bool         `json:"multipleChoice"`
+	ShowResults    string       `json:"showResults"` // "after_vote" or "always"
+	ClosesAt       *time.Time   `json:"closesAt,omitempty"`
+	Options        []PollOption `json:"options"`
+	IsClosed       bool         `json:"isClosed"`
+	HasVoted       bool         `json:"hasVoted"`
+	ResultsHidden  bool         `json:"resultsHidden"`
+	TotalVoters    int          `json:"totalVoters"`
+}
+
+type PollOption struct {
+	Id     int    `json:"id"`
+	Text   string `json:"text"`
+	Votes  int    `json:"votes"`
+	Chosen bool   `json:"chosen"`
+}
+
+type PollVote struct {
+	OptionIds []int `json:"optionIds"`
+}
+
+// PollUpdate is pushed over the notification websocket when someone votes
+type PollUpdate struct {
+	Type   string `json:"type"` // always "poll_update"
+	PostId int    `json:"postId"`
+	Poll   Poll   `json:"poll"`
+}
+
+// ChatLinkPreview is pushed over the message websocket when the preview of a message's link is fetched after the
+// message went out
+type ChatLinkPreview struct {
+	Type        string      `json:"type"` // always "link_preview"
+	MessageId   int         `json:"messageId"`
+	LinkPreview LinkPreview `json:"linkPreview"`
+}
+
+type CommentReaction struct {
+	Reaction string `json:"reaction"`
+}
+
+type LinkPreview struct {
+	Url         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+}
+
+type Report struct {
+	Id              int        `json:"id"`
+	ReporterId      int        `json:"reporterId"`
+	ContentType     string     `json:"contentType"` // "post", "comment", "group_post", "group_comment", "message" or "user"
+	ContentId       int        `json:"contentId"`
+	ReportedUserId  int        `json:"reportedUserId"`
+	Reason          string     `json:"reason"`
+	Details         string     `json:"details"`
+	ContentSnapshot string     `json:"contentSnapshot,omitempty"` // the content as it was when it was reported
+	Status          string     `json:"status"`                    // "open", "actioned" or "dismissed"
+	AssigneeId      int        `json:"assigneeId,omitempty"`
+	Action          string     `json:"action,omitempty"`
+	ResolutionNote  string     `json:"resolutionNote,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	ResolvedAt      *time.Time `json:"resolvedAt,omitempty"`
+	ResolvedBy      int        `json:"resolvedBy,omitempty"`
+}
+
+type ReportAssignment struct {
+	AssigneeId int `json:"assigneeId"`
+}
+
+type ReportResolution struct {
+	Action      string `json:"action"` // "none", "hide_content", "warn_user" or "suspend_user"
+	Note        string `json:"note"`
+	SuspendDays int    `json:"suspendDays,omitempty"`
+}
+
+type AuditLogEntry struct {
+	Id         int       `json:"id"`
+	ActorId    int       `json:"actorId"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"targetType"`
+	TargetId   int       `json:"targetId"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type GroupAuditLogEntry struct {
+	Id         int       `json:"id"`
+	GroupId    int       `json:"groupId"`
+	ActorId    int       `json:"actorId"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"targetType,omitempty"` // "user", "group_post", "event", "invite_link" or "group"
+	TargetId   int       `json:"targetId,omitempty"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// GroupAuditLogFilter narrows the group audit log, empty fields match every entry
+type GroupAuditLogFilter struct {
+	Action     string
+	ActorId    int
+	TargetType string
+	TargetId   int
+}
+
+type RoleChange struct {
+	Role string `json:"role"`
+}
+
+type Suspension struct {
+	Days   int    `json:"days"`
+	Reason string `json:"reason"`
+}
+
+type PlatformStats struct {
+	Users          int `json:"users"`
+	Moderators     int `json:"moderators"`
+	Admins         int `json:"admins"`
+	SuspendedUsers int `json:"suspendedUsers"`
+	ActiveSessions int `json:"activeSessions"`
+	Posts          int `json:"posts"`
+	Comments       int `json:"comments"`
+	Groups         int `json:"groups"`
+	GroupPosts     int `json:"groupPosts"`
+	GroupComments  int `json:"groupComments"`
+	ChatMessages   int `json:"chatMessages"`
+	OpenReports    int `json:"openReports"`
+}
+
+type Story struct {
+	Id             int       `json:"id"`
+	UserId         int       `json:"userId"`
+	CreatorName    string    `json:"creatorName"`
+	ProfilePicture string    `json:"profilePicture"`
+	Content        string    `json:"content"`
+	Media          string    `json:"media,omitempty"`     // url of the image, if the story has one
+	MediaData      string    `json:"mediaData,omitempty"` // base64 data url of the image when creating a story
+	Privacy        string    `json:"privacy"`             // "public", "private" or a comma separated list of user ids, like posts
+	Seen           bool      `json:"seen"`                // whether the viewer has seen it, own stories count as seen
+	ViewCount      int       `json:"viewCount,omitempty"` // only filled in for the author
+	CreatedAt      time.Time `json:"createdAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+type StoryViewer struct {
+	UserId         int       `json:"userId"`
+	Name           string    `json:"name"`
+	ProfilePicture string    `json:"profilePicture"`
+	ViewedAt       time.Time `json:"viewedAt"`
+}
+
+type GroupMember struct {
+	UserId         int    `json:"userId"`
+	Name           string `json:"name"`
+	ProfilePicture string `json:"profilePicture"`
+	Role           string `json:"role"` // "owner", "admin", "moderator" or "member"
+}
+
+// MemberRemoval is the optional reason for removing or banning a group member, bans last Days days or forever when it's 0
+type MemberRemoval struct {
+	Reason string `json:"reason"`
+	Days   int    `json:"days"`
+}
+
+type GroupBan struct {
+	UserId         int        `json:"userId"`
+	Name           string     `json:"name"`
+	ProfilePicture string     `json:"profilePicture"`
+	BannedBy       int        `json:"bannedBy"`
+	Reason         string     `json:"reason,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+}
+
+type GroupInviteLink struct {
+	Id          int        `json:"id"`
+	GroupId     int        `json:"groupId"`
+	Token       string     `json:"token"`
+	CreatedBy   int        `json:"createdBy"`
+	AutoApprove bool       `json:"autoApprove"` // true adds users to the group right away, false creates a join request
+	MaxUses     int        `json:"maxUses"`     // 0 for no limit
+	Uses        int        `json:"uses"`
+	Days        int        `json:"days,omitempty"` // how long a new link stays valid, 0 for no expiry
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	Revoked     bool       `json:"revoked"`
+}
+
+type GroupInviteLinkUse struct {
+	UserId         int       `json:"userId"`
+	Name           string    `json:"name"`
+	ProfilePicture string    `json:"profilePicture"`
+	Status         string    `json:"status"` // "joined" or "requested", then "joined", "declined", "withdrawn" or "expired" once the request ends
+	UsedAt         time.Time `json:"usedAt"`
+}
+
+// InviteLinkPreview is what the holder of an invite link sees before using it
+type InviteLinkPreview struct {
+	Group       Group           `json:"group"`
+	AutoApprove bool            `json:"autoApprove"`
+	Questions   []GroupQuestion `json:"questions,omitempty"` // the membership questions, for links that create a join request
+}
+
+type OwnershipTransfer struct {
+	UserId int `json:"userId"`
+}
+
+// GroupUpdate holds the group details to change, fields left out stay as they are.
+// An empty coverData removes the cover image.
+type GroupUpdate struct {
+	Name         *string `json:"groupName"`
+	Description  *string `json:"groupDescription"`
+	Rules        *string `json:"rules"`
+	Visibility   *string `json:"visibility"`
+	CoverData    *string `json:"coverData"` // base64 data url of the new cover image
+	PostApproval *bool   `json:"postApproval"`
+}
+
+type GroupQuestion struct {
+	Id       int    `json:"id"`
+	Question string `json:"question"`
+}
+
+// GroupQuestions replaces the membership questions of a group
+type GroupQuestions struct {
+	Questions []string `json:"questions"`
+}
+
+// JoinRequestAnswers holds the applicant's answers, in the order of the group's questions
+type JoinRequestAnswers struct {
+	Answers []string `json:"answers"`
+}
+
+type JoinRequestAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// PendingJoinRequest is a request to join a group waiting for an answer, as listed to the approvers
+type PendingJoinRequest struct {
+	UserId         int                 `json:"userId"`
+	Name           string              `json:"name"`
+	ProfilePicture string              `json:"profilePicture"`
+	Answers        []JoinRequestAnswer `json:"answers"`
+	CreatedAt      time.Time           `json:"createdAt"`
+}
+
+// PostRejection holds the optional reason a pending group post was rejected for, it's sent to the author
+type PostRejection struct {
+	Reason string `json:"reason"`
+}